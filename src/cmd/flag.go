@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/urfave/cli"
 )
 
@@ -84,6 +86,82 @@ func FlagDNSResolvers() cli.Flag {
 	}
 }
 
+func FlagGatewayMetricsAddrs() cli.Flag {
+	return cli.StringFlag{
+		Name:   "gateway-metrics-addrs",
+		Usage:  "agent addresses to scrape gateway metrics from for canary analysis, splited by ','",
+		EnvVar: "SWAN_GATEWAY_METRICS_ADDRS",
+	}
+}
+
+func FlagDockerAPIPort() cli.Flag {
+	return cli.IntFlag{
+		Name:   "docker-api-port",
+		Usage:  "tcp port of docker daemon on agents, used to exec into task containers",
+		Value:  2375,
+		EnvVar: "SWAN_DOCKER_API_PORT",
+	}
+}
+
+func FlagDockerTLSCaCert() cli.Flag {
+	return cli.StringFlag{
+		Name:   "docker-tls-cacert",
+		Usage:  "ca cert to verify docker daemon on agents, required to exec into task containers",
+		EnvVar: "SWAN_DOCKER_TLS_CACERT",
+	}
+}
+
+func FlagDockerTLSCert() cli.Flag {
+	return cli.StringFlag{
+		Name:   "docker-tls-cert",
+		Usage:  "client cert presented to docker daemon on agents",
+		EnvVar: "SWAN_DOCKER_TLS_CERT",
+	}
+}
+
+func FlagDockerTLSKey() cli.Flag {
+	return cli.StringFlag{
+		Name:   "docker-tls-key",
+		Usage:  "key of the client cert presented to docker daemon on agents",
+		EnvVar: "SWAN_DOCKER_TLS_KEY",
+	}
+}
+
+func FlagExecToken() cli.Flag {
+	return cli.StringFlag{
+		Name:   "exec-token",
+		Usage:  "bearer token required to exec into task containers, exec is disabled if not set, requires --docker-tls-*",
+		EnvVar: "SWAN_EXEC_TOKEN",
+	}
+}
+
+func FlagPreemptionDelay() cli.Flag {
+	return cli.DurationFlag{
+		Name:   "preemption-delay",
+		Usage:  "slots pending for offers longer than this preempt tasks of lower priority apps, 0 disables preemption",
+		Value:  2 * time.Minute,
+		EnvVar: "SWAN_PREEMPTION_DELAY",
+	}
+}
+
+func FlagOfferRefuseSeconds() cli.Flag {
+	return cli.Float64Flag{
+		Name:   "offer-refuse-seconds",
+		Usage:  "seconds mesos refrains from offering resources declined, doubled for agents whose offers keep declined",
+		Value:  5,
+		EnvVar: "SWAN_OFFER_REFUSE_SECONDS",
+	}
+}
+
+func FlagOfferMaxRefuseSeconds() cli.Flag {
+	return cli.Float64Flag{
+		Name:   "offer-max-refuse-seconds",
+		Usage:  "max seconds mesos refrains from offering resources declined",
+		Value:  60,
+		EnvVar: "SWAN_OFFER_MAX_REFUSE_SECONDS",
+	}
+}
+
 func FlagMesosZkPath() cli.Flag {
 	return cli.StringFlag{
 		Name:   "mesos-zk-path",
@@ -24,6 +24,15 @@ func ManagerCmd() cli.Command {
 	managerCmd.Flags = append(managerCmd.Flags, FlagZkPath())
 	managerCmd.Flags = append(managerCmd.Flags, FlagMesosZkPath())
 	managerCmd.Flags = append(managerCmd.Flags, FlagLogLevel())
+	managerCmd.Flags = append(managerCmd.Flags, FlagGatewayMetricsAddrs())
+	managerCmd.Flags = append(managerCmd.Flags, FlagDockerAPIPort())
+	managerCmd.Flags = append(managerCmd.Flags, FlagDockerTLSCaCert())
+	managerCmd.Flags = append(managerCmd.Flags, FlagDockerTLSCert())
+	managerCmd.Flags = append(managerCmd.Flags, FlagDockerTLSKey())
+	managerCmd.Flags = append(managerCmd.Flags, FlagExecToken())
+	managerCmd.Flags = append(managerCmd.Flags, FlagPreemptionDelay())
+	managerCmd.Flags = append(managerCmd.Flags, FlagOfferRefuseSeconds())
+	managerCmd.Flags = append(managerCmd.Flags, FlagOfferMaxRefuseSeconds())
 
 	return managerCmd
 }
@@ -20,6 +20,24 @@ type ManagerConfig struct {
 
 	MesosZkPath *url.URL `json:"mesosZkPath"`
 	ZkPath      *url.URL `json:"zkPath"`
+
+	// agent addresses where gateway metrics scraped from
+	GatewayMetricsAddrs []string `json:"gatewayMetricsAddrs"`
+
+	// tcp port of docker daemon on agents, used to exec into task containers
+	DockerAPIPort int `json:"dockerAPIPort"`
+	// certs to talk tls to docker daemon on agents, required by exec
+	DockerTLSCaCert string `json:"dockerTLSCaCert"`
+	DockerTLSCert   string `json:"dockerTLSCert"`
+	DockerTLSKey    string `json:"dockerTLSKey"`
+	// bearer token required to exec into task containers, exec is disabled if empty
+	// and requires tls to docker daemon on agents
+	ExecToken string `json:"-"`
+	// slots pending longer than this preempt tasks of lower priority, 0 disables preemption
+	PreemptionDelay time.Duration `json:"preemptionDelay"`
+	// filter of declined offers, doubled for agents whose offers keep declined
+	OfferRefuseSeconds    float64 `json:"offerRefuseSeconds"`
+	OfferMaxRefuseSeconds float64 `json:"offerMaxRefuseSeconds"`
 }
 
 type AgentConfig struct {
@@ -149,6 +167,7 @@ func NewManagerConfig(c *cli.Context) (ManagerConfig, error) {
 		ListenAddr:         "0.0.0.0:9999",
 		MesosFrameworkUser: "root",
 		Hostname:           Hostname(),
+		DockerAPIPort:      2375,
 	}
 
 	managerConfig.MesosZkPath, err = url.Parse(c.String("mesos-zk-path"))
@@ -180,6 +199,32 @@ func NewManagerConfig(c *cli.Context) (ManagerConfig, error) {
 		managerConfig.LogLevel = c.String("log-level")
 	}
 
+	if c.String("gateway-metrics-addrs") != "" {
+		managerConfig.GatewayMetricsAddrs = strings.Split(c.String("gateway-metrics-addrs"), ",")
+	}
+
+	if c.Int("docker-api-port") > 0 {
+		managerConfig.DockerAPIPort = c.Int("docker-api-port")
+	}
+
+	managerConfig.DockerTLSCaCert = c.String("docker-tls-cacert")
+	managerConfig.DockerTLSCert = c.String("docker-tls-cert")
+	managerConfig.DockerTLSKey = c.String("docker-tls-key")
+	if managerConfig.DockerTLSCaCert != "" || managerConfig.DockerTLSCert != "" || managerConfig.DockerTLSKey != "" {
+		if managerConfig.DockerTLSCaCert == "" || managerConfig.DockerTLSCert == "" || managerConfig.DockerTLSKey == "" {
+			return managerConfig, errors.New("--docker-tls-cacert, --docker-tls-cert and --docker-tls-key should be given together")
+		}
+	}
+
+	// docker daemon listening on tcp without tls would give away agents
+	managerConfig.ExecToken = c.String("exec-token")
+	if managerConfig.ExecToken != "" && managerConfig.DockerTLSCaCert == "" {
+		return managerConfig, errors.New("--exec-token requires --docker-tls-cacert, --docker-tls-cert and --docker-tls-key")
+	}
+	managerConfig.PreemptionDelay = c.Duration("preemption-delay")
+	managerConfig.OfferRefuseSeconds = c.Float64("offer-refuse-seconds")
+	managerConfig.OfferMaxRefuseSeconds = c.Float64("offer-max-refuse-seconds")
+
 	return managerConfig, nil
 }
 
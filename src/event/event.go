@@ -14,6 +14,12 @@ const (
 	EventTypeTaskHealthy      = "task_healthy"
 	EventTypeTaskWeightChange = "task_weight_change"
 	EventTypeTaskUnhealthy    = "task_unhealthy"
+	// task killed for being unhealthy too long, to be relaunched by restart policy
+	EventTypeTaskUnhealthyKilled = "task_unhealthy_killed"
+	// task killed for a pending slot of higher priority, to be relaunched by restart policy
+	EventTypeTaskPreempted = "task_preempted"
+	// task killed to move off an agent going into maintenance, to be relaunched by restart policy
+	EventTypeTaskMigrated = "task_migrated"
 
 	EventTypeTaskStatePendingOffer   = "task_state_pending_offer"
 	EventTypeTaskStatePendingKill    = "task_state_pending_killed"
@@ -38,8 +44,12 @@ const (
 	EventTypeAppStateNormal       = "app_state_normal"
 	EventTypeAppStateUpdating     = "app_state_updating"
 	EventTypeAppStateCancelUpdate = "app_state_cancel_update"
+	EventTypeAppStateCanary       = "app_state_canary"
+	EventTypeAppStateBlueGreen    = "app_state_blue_green"
 	EventTypeAppStateScaleUp      = "app_state_scale_up"
 	EventTypeAppStateScaleDown    = "app_state_scale_down"
+	EventTypeAppStateSuspended    = "app_state_suspended"
+	EventTypeAppStateRestarting   = "app_state_restarting"
 )
 
 type Event struct {
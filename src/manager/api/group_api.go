@@ -0,0 +1,216 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/config"
+	"github.com/Dataman-Cloud/swan/src/manager/apiserver"
+	"github.com/Dataman-Cloud/swan/src/manager/apiserver/metrics"
+	"github.com/Dataman-Cloud/swan/src/manager/scheduler"
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/emicklei/go-restful"
+)
+
+type GroupService struct {
+	Scheduler *scheduler.Scheduler
+	apiServer *apiserver.ApiServer
+}
+
+func NewAndInstallGroupService(apiServer *apiserver.ApiServer, eng *scheduler.Scheduler) {
+	groupService := &GroupService{
+		Scheduler: eng,
+		apiServer: apiServer,
+	}
+	apiserver.Install(apiServer, groupService)
+}
+
+func (api *GroupService) Register(container *restful.Container) {
+	ws := new(restful.WebService)
+	ws.
+		ApiVersion(config.API_PREFIX).
+		Path(config.API_PREFIX + "/groups").
+		Doc("App group management").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/").To(metrics.InstrumentRouteFunc("GET", "Groups", api.ListGroups)).
+		// docs
+		Doc("List Groups").
+		Operation("listGroups").
+		Returns(200, "OK", []types.Group{}))
+	ws.Route(ws.POST("/").To(metrics.InstrumentRouteFunc("POST", "Group", api.CreateGroup)).
+		// docs
+		Doc("Create Group").
+		Operation("createGroup").
+		Returns(201, "OK", types.Group{}).
+		Returns(400, "BadRequest", nil).
+		Reads(types.GroupVersion{}).
+		Writes(types.Group{}))
+	ws.Route(ws.GET("/{group_id}").To(metrics.InstrumentRouteFunc("GET", "Group", api.GetGroup)).
+		// docs
+		Doc("Get a Group").
+		Operation("getGroup").
+		Param(ws.PathParameter("group_id", "identifier of the group").DataType("string")).
+		Returns(200, "OK", types.Group{}).
+		Returns(404, "NotFound", nil).
+		Writes(types.Group{}))
+	ws.Route(ws.PUT("/{group_id}").To(metrics.InstrumentRouteFunc("PUT", "Group", api.UpdateGroup)).
+		// docs
+		Doc("Update Group").
+		Operation("updateGroup").
+		Returns(200, "OK", types.Group{}).
+		Returns(400, "BadRequest", nil).
+		Reads(types.GroupVersion{}).
+		Writes(types.Group{}).
+		Param(ws.PathParameter("group_id", "identifier of the group").DataType("string")))
+	ws.Route(ws.PATCH("/{group_id}/scale").To(metrics.InstrumentRouteFunc("PATCH", "Group", api.ScaleGroup)).
+		// docs
+		Doc("Scale Apps of Group").
+		Operation("scaleGroup").
+		Reads(types.ScaleGroupParam{}).
+		Returns(200, "OK", nil).
+		Returns(400, "BadRequest", nil).
+		Param(ws.PathParameter("group_id", "identifier of the group").DataType("string")))
+	ws.Route(ws.DELETE("/{group_id}").To(metrics.InstrumentRouteFunc("DELETE", "Group", api.DeleteGroup)).
+		// docs
+		Doc("Delete Group").
+		Operation("deleteGroup").
+		Returns(204, "OK", nil).
+		Returns(404, "NotFound", nil).
+		Param(ws.PathParameter("group_id", "identifier of the group").DataType("string")))
+
+	container.Add(ws)
+}
+
+func (api *GroupService) ListGroups(request *restful.Request, response *restful.Response) {
+	groupsRet := make([]*types.Group, 0)
+	for _, group := range api.Scheduler.ListGroups() {
+		groupsRet = append(groupsRet, api.formGroup(group))
+	}
+
+	response.WriteEntity(groupsRet)
+}
+
+func (api *GroupService) CreateGroup(request *restful.Request, response *restful.Response) {
+	var spec types.GroupVersion
+
+	err := request.ReadEntity(&spec)
+	if err != nil {
+		logrus.Errorf("Create group error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	group, err := api.Scheduler.CreateGroup(&spec)
+	if err != nil {
+		logrus.Errorf("Create group error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteHeaderAndEntity(http.StatusCreated, api.formGroup(group))
+}
+
+func (api *GroupService) GetGroup(request *restful.Request, response *restful.Response) {
+	group, err := api.Scheduler.InspectGroup(request.PathParameter("group_id"))
+	if err != nil {
+		logrus.Debugf("Get group error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteEntity(api.formGroup(group))
+}
+
+func (api *GroupService) UpdateGroup(request *restful.Request, response *restful.Response) {
+	var spec types.GroupVersion
+
+	err := request.ReadEntity(&spec)
+	if err != nil {
+		logrus.Errorf("Update group error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	group, err := api.Scheduler.UpdateGroup(request.PathParameter("group_id"), &spec)
+	if err != nil {
+		logrus.Errorf("Update group error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteEntity(api.formGroup(group))
+}
+
+func (api *GroupService) ScaleGroup(request *restful.Request, response *restful.Response) {
+	var param types.ScaleGroupParam
+
+	err := request.ReadEntity(&param)
+	if err != nil {
+		logrus.Errorf("Scale group error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	err = api.Scheduler.ScaleGroup(request.PathParameter("group_id"), &param)
+	if err != nil {
+		logrus.Errorf("Scale group error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+}
+
+func (api *GroupService) DeleteGroup(request *restful.Request, response *restful.Response) {
+	err := api.Scheduler.DeleteGroup(request.PathParameter("group_id"))
+	if err != nil {
+		logrus.Errorf("Delete group error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func (api *GroupService) formGroup(group *store.Group) *types.Group {
+	groupRet := &types.Group{
+		ID:        group.ID,
+		Name:      group.Name,
+		RunAs:     group.RunAs,
+		ClusterID: group.ClusterID,
+		State:     group.State,
+		Message:   group.Message,
+		Timeout:   group.Timeout,
+		Apps:      make([]*types.GroupApp, 0),
+		Created:   time.Unix(0, group.CreatedAt),
+		Updated:   time.Unix(0, group.UpdatedAt),
+	}
+
+	for _, member := range group.Members {
+		groupApp := &types.GroupApp{
+			AppID:     member.AppID,
+			AppName:   member.AppName,
+			DependsOn: member.DependsOn,
+		}
+
+		if app := api.Scheduler.AppStorage.Get(member.AppID); app != nil {
+			groupApp.State = app.StateMachine.ReadableState()
+			groupApp.Instances = int(app.CurrentVersion.Instances)
+			for _, slot := range app.GetSlots() {
+				if slot.StateIs(state.SLOT_STATE_TASK_RUNNING) {
+					groupApp.RunningInstances += 1
+				}
+			}
+		}
+
+		groupRet.Apps = append(groupRet.Apps, groupApp)
+	}
+
+	return groupRet
+}
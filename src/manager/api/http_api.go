@@ -2,15 +2,21 @@ package api
 
 import (
 	"crypto/md5"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Dataman-Cloud/swan/src/config"
 	"github.com/Dataman-Cloud/swan/src/manager/apiserver"
 	"github.com/Dataman-Cloud/swan/src/manager/apiserver/metrics"
+	"github.com/Dataman-Cloud/swan/src/manager/connector"
+	"github.com/Dataman-Cloud/swan/src/manager/dockerexec"
+	"github.com/Dataman-Cloud/swan/src/manager/sandbox"
 	"github.com/Dataman-Cloud/swan/src/manager/scheduler"
 	"github.com/Dataman-Cloud/swan/src/manager/state"
 	"github.com/Dataman-Cloud/swan/src/types"
@@ -21,15 +27,32 @@ import (
 	"github.com/emicklei/go-restful"
 )
 
+const (
+	DEFAULT_TASK_HISTORY_PAGE_SIZE = 20
+	DEFAULT_TASK_LOGS_TAIL         = 100
+	TASK_LOGS_FOLLOW_INTERVAL      = time.Second
+)
+
 type AppService struct {
 	Scheduler *scheduler.Scheduler
 	apiServer *apiserver.ApiServer
+
+	dockerAPIPort   int
+	dockerTLSCaCert string
+	dockerTLSCert   string
+	dockerTLSKey    string
+	execToken       string
 }
 
-func NewAndInstallAppService(apiServer *apiserver.ApiServer, eng *scheduler.Scheduler) {
+func NewAndInstallAppService(apiServer *apiserver.ApiServer, eng *scheduler.Scheduler, conf config.ManagerConfig) {
 	appService := &AppService{
-		Scheduler: eng,
-		apiServer: apiServer,
+		Scheduler:       eng,
+		apiServer:       apiServer,
+		dockerAPIPort:   conf.DockerAPIPort,
+		dockerTLSCaCert: conf.DockerTLSCaCert,
+		dockerTLSCert:   conf.DockerTLSCert,
+		dockerTLSKey:    conf.DockerTLSKey,
+		execToken:       conf.ExecToken,
 	}
 	apiserver.Install(apiServer, appService)
 }
@@ -86,6 +109,7 @@ func (api *AppService) Register(container *restful.Container) {
 	ws.Route(ws.PATCH("/{app_id}/scale-down").To(metrics.InstrumentRouteFunc("PATCH", "App", api.ScaleDown)).
 		// docs
 		Doc("Scale Down App").
+		Notes("slots at the top are moved into the indices left by the removed ones, their dns names change accordingly").
 		Operation("scaleDown").
 		Reads(types.ScaleDownParam{}).
 		Returns(200, "OK", nil).
@@ -100,6 +124,16 @@ func (api *AppService) Register(container *restful.Container) {
 		Reads(types.Version{}).
 		Writes(types.App{}).
 		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")))
+	ws.Route(ws.POST("/{app_id}/update-preview").To(metrics.InstrumentRouteFunc("POST", "App", api.PreviewUpdateApp)).
+		// docs
+		Doc("Preview changes of updating App without applying").
+		Operation("previewUpdateApp").
+		Returns(200, "OK", types.UpdatePreview{}).
+		Returns(400, "BadRequest", nil).
+		Returns(404, "NotFound", nil).
+		Reads(types.Version{}).
+		Writes(types.UpdatePreview{}).
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")))
 	ws.Route(ws.PATCH("/{app_id}/weights").To(metrics.InstrumentRouteFunc("PATCH", "App", api.UpdateWeights)).
 		// docs
 		Doc("Update Slot Weight").
@@ -123,6 +157,39 @@ func (api *AppService) Register(container *restful.Container) {
 		Returns(400, "BadRequest", nil).
 		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")))
 
+	ws.Route(ws.POST("/{app_id}/restart").To(metrics.InstrumentRouteFunc("POST", "App", api.RestartApp)).
+		// docs
+		Doc("Rolling restart App with current version").
+		Operation("restartApp").
+		Returns(200, "OK", nil).
+		Returns(400, "BadRequest", nil).
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")))
+	ws.Route(ws.PATCH("/{app_id}/suspend").To(metrics.InstrumentRouteFunc("PATCH", "App", api.SuspendApp)).
+		// docs
+		Doc("Suspend App, kill all tasks but keep slots").
+		Operation("suspendApp").
+		Returns(200, "OK", nil).
+		Returns(400, "BadRequest", nil).
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")))
+	ws.Route(ws.PATCH("/{app_id}/resume").To(metrics.InstrumentRouteFunc("PATCH", "App", api.ResumeApp)).
+		// docs
+		Doc("Resume suspended App").
+		Operation("resumeApp").
+		Returns(200, "OK", nil).
+		Returns(400, "BadRequest", nil).
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")))
+
+	ws.Route(ws.POST("/{app_id}/rollback").To(metrics.InstrumentRouteFunc("POST", "App", api.RollbackApp)).
+		// docs
+		Doc("Rollback App to a previous version").
+		Operation("rollbackApp").
+		Returns(200, "OK", types.App{}).
+		Returns(400, "BadRequest", nil).
+		Returns(404, "NotFound", nil).
+		Reads(types.RollbackParam{}).
+		Writes(types.App{}).
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")))
+
 	ws.Route(ws.GET("/{app_id}/tasks/{task_id}").To(metrics.InstrumentRouteFunc("GET", "AppTask", api.GetAppTask)).
 		// docs
 		Doc("Get a task in the given App").
@@ -132,6 +199,58 @@ func (api *AppService) Register(container *restful.Container) {
 		Returns(200, "OK", types.Task{}).
 		Returns(404, "NotFound", nil))
 
+	ws.Route(ws.GET("/{app_id}/tasks/{task_id}/health").To(metrics.InstrumentRouteFunc("GET", "AppTaskHealth", api.GetAppTaskHealth)).
+		// docs
+		Doc("Get health check results of a task").
+		Operation("getAppTaskHealth").
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")).
+		Param(ws.PathParameter("task_id", "identifier of the task").DataType("int")).
+		Returns(200, "OK", types.TaskHealth{}).
+		Returns(404, "NotFound", nil))
+
+	ws.Route(ws.GET("/{app_id}/tasks/{task_id}/history").To(metrics.InstrumentRouteFunc("GET", "AppTaskHistory", api.GetAppTaskHistory)).
+		// docs
+		Doc("List archived tasks of a slot, the latest first").
+		Operation("getAppTaskHistory").
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")).
+		Param(ws.PathParameter("task_id", "identifier of the task").DataType("int")).
+		Param(ws.QueryParameter("offset", "number of latest tasks skipped, default 0").DataType("int")).
+		Param(ws.QueryParameter("limit", "max number of tasks returned, default 20").DataType("int")).
+		Returns(200, "OK", types.TaskHistoryPage{}).
+		Returns(400, "BadRequest", nil).
+		Returns(404, "NotFound", nil))
+
+	ws.Route(ws.GET("/{app_id}/tasks/{task_id}/logs").To(metrics.InstrumentRouteFunc("GET", "AppTaskLogs", api.GetAppTaskLogs)).
+		// docs
+		Doc("Get stdout or stderr of a task from its sandbox on mesos agent").
+		Operation("getAppTaskLogs").
+		Produces("text/plain").
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")).
+		Param(ws.PathParameter("task_id", "identifier of the task").DataType("int")).
+		Param(ws.QueryParameter("stream", "stdout or stderr, default stdout").DataType("string")).
+		Param(ws.QueryParameter("tail", "number of last lines, default 100").DataType("int")).
+		Param(ws.QueryParameter("follow", "keep streaming new output, default false").DataType("boolean")).
+		Param(ws.QueryParameter("history", "id of an archived task of the slot, default the current task").DataType("string")).
+		Returns(200, "OK", nil).
+		Returns(400, "BadRequest", nil).
+		Returns(404, "NotFound", nil))
+
+	ws.Route(ws.POST("/{app_id}/tasks/{task_id}/exec").To(metrics.InstrumentRouteFunc("POST", "AppTaskExec", api.ExecAppTask)).
+		// docs
+		Doc("Run a command in the container of a running task, output streamed as server-sent events").
+		Operation("execAppTask").
+		Produces("text/event-stream").
+		Param(ws.HeaderParameter("Authorization", "Bearer token set by --exec-token of manager").DataType("string")).
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")).
+		Param(ws.PathParameter("task_id", "identifier of the task").DataType("int")).
+		Reads(types.ExecParam{}).
+		Returns(200, "OK", nil).
+		Returns(400, "BadRequest", nil).
+		Returns(401, "Unauthorized", nil).
+		Returns(403, "Forbidden", nil).
+		Returns(404, "NotFound", nil).
+		Returns(409, "Conflict", nil))
+
 	ws.Route(ws.PATCH("/{app_id}/tasks/{task_id}/weight").To(metrics.InstrumentRouteFunc("GET", "AppTask", api.UpdateAppTaskWeight)).
 		// docs
 		Doc("Update weight of a task").
@@ -141,6 +260,15 @@ func (api *AppService) Register(container *restful.Container) {
 		Returns(200, "OK", types.Task{}).
 		Returns(404, "NotFound", nil))
 
+	ws.Route(ws.POST("/{app_id}/tasks/{task_id}/restart").To(metrics.InstrumentRouteFunc("POST", "AppTask", api.RestartAppTask)).
+		// docs
+		Doc("Restart a task with current version").
+		Operation("restartAppTask").
+		Param(ws.PathParameter("app_id", "identifier of the app").DataType("string")).
+		Param(ws.PathParameter("task_id", "identifier of the task").DataType("int")).
+		Returns(200, "OK", nil).
+		Returns(400, "BadRequest", nil))
+
 	ws.Route(ws.GET("/{app_id}/versions").To(metrics.InstrumentRouteFunc("GET", "AppVersions", api.GetAppVersions)).
 		// docs
 		Doc("Get all versions in the given App").
@@ -275,7 +403,7 @@ func (api *AppService) ScaleDown(request *restful.Request, response *restful.Res
 		response.WriteError(http.StatusBadRequest, err)
 	}
 
-	err = api.Scheduler.ScaleDown(request.PathParameter("app_id"), param.Instances)
+	err = api.Scheduler.ScaleDown(request.PathParameter("app_id"), param.Instances, param.Indices, param.IPs)
 	if err != nil {
 		logrus.Errorf("Scale down app error: %s", err.Error())
 		response.WriteError(http.StatusBadRequest, err)
@@ -329,6 +457,53 @@ func (api *AppService) UpdateApp(request *restful.Request, response *restful.Res
 	return
 }
 
+func (api *AppService) PreviewUpdateApp(request *restful.Request, response *restful.Response) {
+	var version types.Version
+
+	err := request.ReadEntity(&version)
+	if err != nil {
+		logrus.Errorf("Preview update app error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	appID := request.PathParameter("app_id")
+	preview, err := api.Scheduler.PreviewUpdateApp(appID, &version)
+	if err != nil {
+		logrus.Errorf("Preview update app[%s] error: %s", appID, err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteEntity(preview)
+}
+
+func (api *AppService) RollbackApp(request *restful.Request, response *restful.Response) {
+	var param types.RollbackParam
+
+	err := request.ReadEntity(&param)
+	if err != nil {
+		logrus.Errorf("Rollback app error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	appID := request.PathParameter("app_id")
+	if err := api.Scheduler.RollbackApp(appID, param.VersionID); err != nil {
+		logrus.Errorf("Rollback app[%s] error: %s", appID, err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	app, err := api.Scheduler.InspectApp(appID)
+	if err != nil {
+		logrus.Errorf("Inspect app[%s] error: %s", appID, err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+	response.WriteEntity(FormAppRetWithVersions(app))
+}
+
 func (api *AppService) UpdateWeights(request *restful.Request, response *restful.Response) {
 	var param types.UpdateWeightsParam
 
@@ -347,7 +522,7 @@ func (api *AppService) UpdateWeights(request *restful.Request, response *restful
 	for index, weight := range param.Weights {
 		indexInt, err := strconv.Atoi(index)
 		if err != nil {
-			logrus.Errorf("fails to update weight", err.Error())
+			logrus.Errorf("fails to update weight: %s", err.Error())
 			response.WriteError(http.StatusBadRequest, err)
 			return
 		}
@@ -388,6 +563,53 @@ func (api *AppService) CancelUpdate(request *restful.Request, response *restful.
 	response.Write([]byte("Update canceled"))
 }
 
+func (api *AppService) RestartApp(request *restful.Request, response *restful.Response) {
+	err := api.Scheduler.RestartApp(request.PathParameter("app_id"))
+	if err != nil {
+		logrus.Errorf("Restart app error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func (api *AppService) RestartAppTask(request *restful.Request, response *restful.Response) {
+	task_index, err := strconv.Atoi(request.PathParameter("task_id"))
+	if err != nil {
+		logrus.Errorf("Get task index err: %s", err.Error())
+		response.WriteErrorString(http.StatusBadRequest, "Get task index err: "+err.Error())
+		return
+	}
+
+	err = api.Scheduler.RestartTask(request.PathParameter("app_id"), task_index)
+	if err != nil {
+		logrus.Errorf("Restart task error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func (api *AppService) SuspendApp(request *restful.Request, response *restful.Response) {
+	err := api.Scheduler.SuspendApp(request.PathParameter("app_id"))
+	if err != nil {
+		logrus.Errorf("Suspend app error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func (api *AppService) ResumeApp(request *restful.Request, response *restful.Response) {
+	err := api.Scheduler.ResumeApp(request.PathParameter("app_id"))
+	if err != nil {
+		logrus.Errorf("Resume app error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
 func (api *AppService) GetAppTask(request *restful.Request, response *restful.Response) {
 	app, err := api.Scheduler.InspectApp(request.PathParameter("app_id"))
 	if err != nil {
@@ -413,6 +635,320 @@ func (api *AppService) GetAppTask(request *restful.Request, response *restful.Re
 	response.WriteEntity(appTaskRet)
 }
 
+func (api *AppService) GetAppTaskHealth(request *restful.Request, response *restful.Response) {
+	app, err := api.Scheduler.InspectApp(request.PathParameter("app_id"))
+	if err != nil {
+		logrus.Errorf("Get app task health error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	index, err := strconv.Atoi(request.PathParameter("task_id"))
+	if err != nil {
+		logrus.Errorf("Get task index err: %s", err.Error())
+		response.WriteErrorString(http.StatusBadRequest, "Get task index err: "+err.Error())
+		return
+	}
+
+	slot, found := app.GetSlot(index)
+	if !found {
+		logrus.Errorf("slot not found: %d", index)
+		response.WriteErrorString(http.StatusNotFound, "slot not found: "+strconv.Itoa(index))
+		return
+	}
+
+	// no health check result reported yet
+	health := slot.Health()
+	if health == nil {
+		health = &types.TaskHealth{
+			Healthy:  slot.Healthy(),
+			Outcomes: make([]*types.HealthCheckOutcome, 0),
+		}
+	}
+
+	response.WriteEntity(health)
+}
+
+func (api *AppService) GetAppTaskHistory(request *restful.Request, response *restful.Response) {
+	app, err := api.Scheduler.InspectApp(request.PathParameter("app_id"))
+	if err != nil {
+		logrus.Errorf("Get app task history error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	index, err := strconv.Atoi(request.PathParameter("task_id"))
+	if err != nil {
+		logrus.Errorf("Get task index err: %s", err.Error())
+		response.WriteErrorString(http.StatusBadRequest, "Get task index err: "+err.Error())
+		return
+	}
+
+	offset, limit := 0, DEFAULT_TASK_HISTORY_PAGE_SIZE
+	if param := request.QueryParameter("offset"); param != "" {
+		if offset, err = strconv.Atoi(param); err != nil || offset < 0 {
+			logrus.Errorf("Get task history err: invalid offset %s", param)
+			response.WriteErrorString(http.StatusBadRequest, "invalid offset: "+param)
+			return
+		}
+	}
+	if param := request.QueryParameter("limit"); param != "" {
+		if limit, err = strconv.Atoi(param); err != nil || limit <= 0 {
+			logrus.Errorf("Get task history err: invalid limit %s", param)
+			response.WriteErrorString(http.StatusBadRequest, "invalid limit: "+param)
+			return
+		}
+	}
+
+	slot, found := app.GetSlot(index)
+	if !found {
+		logrus.Errorf("slot not found: %d", index)
+		response.WriteErrorString(http.StatusNotFound, "slot not found: "+strconv.Itoa(index))
+		return
+	}
+
+	page := &types.TaskHistoryPage{
+		Total:   len(slot.TaskHistory),
+		Offset:  offset,
+		Limit:   limit,
+		History: make([]*types.TaskHistory, 0),
+	}
+
+	for i := len(slot.TaskHistory) - 1 - offset; i >= 0 && len(page.History) < limit; i-- {
+		history := FormTaskHistory(slot.TaskHistory[i])
+		history.AppID = app.ID
+		page.History = append(page.History, history)
+	}
+
+	response.WriteEntity(page)
+}
+
+func (api *AppService) GetAppTaskLogs(request *restful.Request, response *restful.Response) {
+	app, err := api.Scheduler.InspectApp(request.PathParameter("app_id"))
+	if err != nil {
+		logrus.Errorf("Get app task logs error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	index, err := strconv.Atoi(request.PathParameter("task_id"))
+	if err != nil {
+		logrus.Errorf("Get task index err: %s", err.Error())
+		response.WriteErrorString(http.StatusBadRequest, "Get task index err: "+err.Error())
+		return
+	}
+
+	stream := request.QueryParameter("stream")
+	if stream == "" {
+		stream = "stdout"
+	}
+	if stream != "stdout" && stream != "stderr" {
+		response.WriteErrorString(http.StatusBadRequest, "stream should be stdout or stderr")
+		return
+	}
+
+	tail := DEFAULT_TASK_LOGS_TAIL
+	if param := request.QueryParameter("tail"); param != "" {
+		if tail, err = strconv.Atoi(param); err != nil || tail <= 0 {
+			response.WriteErrorString(http.StatusBadRequest, "invalid tail: "+param)
+			return
+		}
+	}
+	follow := request.QueryParameter("follow") == "true"
+
+	slot, found := app.GetSlot(index)
+	if !found {
+		logrus.Errorf("slot not found: %d", index)
+		response.WriteErrorString(http.StatusNotFound, "slot not found: "+strconv.Itoa(index))
+		return
+	}
+
+	task := slot.CurrentTask
+	if id := request.QueryParameter("history"); id != "" {
+		task = nil
+		for _, t := range slot.TaskHistory {
+			if t.ID == id {
+				task = t
+			}
+		}
+	}
+	if task == nil || task.AgentID == "" {
+		response.WriteErrorString(http.StatusNotFound, "task not launched on any agent")
+		return
+	}
+
+	box, err := sandbox.Locate(connector.Instance().MesosLeader, task.AgentID, task.ID)
+	if err != nil {
+		logrus.Errorf("Locate sandbox of task %s err: %s", task.ID, err.Error())
+		response.WriteErrorString(http.StatusNotFound, "locate sandbox err: "+err.Error())
+		return
+	}
+
+	data, offset, err := box.Tail(stream, tail)
+	if err != nil {
+		logrus.Errorf("Read %s of task %s err: %s", stream, task.ID, err.Error())
+		response.WriteErrorString(http.StatusBadGateway, "read sandbox err: "+err.Error())
+		return
+	}
+
+	response.AddHeader("Content-Type", "text/plain; charset=utf-8")
+	response.Write(data)
+	if !follow {
+		return
+	}
+
+	flusher, _ := response.ResponseWriter.(http.Flusher)
+	ticker := time.NewTicker(TASK_LOGS_FOLLOW_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		select {
+		case <-request.Request.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		data, err := box.Read(stream, offset, sandbox.READ_CHUNK_SIZE)
+		if err != nil {
+			logrus.Errorf("Follow %s of task %s err: %s", stream, task.ID, err.Error())
+			return
+		}
+		offset += int64(len(data))
+
+		// nothing more to come once the task is gone
+		if len(data) == 0 && (slot.CurrentTask != task || !slot.Dispatched()) {
+			return
+		}
+
+		response.Write(data)
+	}
+}
+
+func (api *AppService) ExecAppTask(request *restful.Request, response *restful.Response) {
+	if api.execToken == "" {
+		response.WriteErrorString(http.StatusForbidden, "exec is disabled, start manager with --exec-token to enable it")
+		return
+	}
+
+	auth := request.HeaderParameter("Authorization")
+	token := strings.TrimPrefix(auth, "Bearer ")
+	if !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(token), []byte(api.execToken)) != 1 {
+		logrus.Warnf("Exec into task of app %s with invalid token from %s", request.PathParameter("app_id"), request.Request.RemoteAddr)
+		response.WriteErrorString(http.StatusUnauthorized, "invalid exec token")
+		return
+	}
+
+	var param types.ExecParam
+	if err := request.ReadEntity(&param); err != nil {
+		logrus.Errorf("Exec into task error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	if len(param.Cmd) == 0 {
+		response.WriteErrorString(http.StatusBadRequest, "cmd required")
+		return
+	}
+
+	app, err := api.Scheduler.InspectApp(request.PathParameter("app_id"))
+	if err != nil {
+		logrus.Errorf("Get app task error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	index, err := strconv.Atoi(request.PathParameter("task_id"))
+	if err != nil {
+		logrus.Errorf("Get task index err: %s", err.Error())
+		response.WriteErrorString(http.StatusBadRequest, "Get task index err: "+err.Error())
+		return
+	}
+
+	slot, found := app.GetSlot(index)
+	if !found {
+		logrus.Errorf("slot not found: %d", index)
+		response.WriteErrorString(http.StatusNotFound, "slot not found: "+strconv.Itoa(index))
+		return
+	}
+
+	task := slot.CurrentTask
+	if !slot.StateIs(state.SLOT_STATE_TASK_RUNNING) || task == nil {
+		response.WriteErrorString(http.StatusConflict, "task is not running")
+		return
+	}
+
+	// container id is reported by the executor in status data of the task
+	container := task.ContainerId
+	if container == "" {
+		container = task.ContainerName
+	}
+	if container == "" {
+		response.WriteErrorString(http.StatusConflict, "container of task not reported yet")
+		return
+	}
+
+	logrus.Infof("Exec %q into task %s from %s", param.Cmd, task.ID, request.Request.RemoteAddr)
+
+	response.AddHeader("Content-Type", "text/event-stream")
+	response.AddHeader("Cache-Control", "no-cache")
+	response.WriteHeader(http.StatusOK)
+
+	flusher, _ := response.ResponseWriter.(http.Flusher)
+	stdout := &sseWriter{w: response, flusher: flusher, event: "stdout"}
+	stderr := &sseWriter{w: response, flusher: flusher, event: "stderr"}
+
+	// the command is left running in the container if the client goes away
+	client, err := api.dockerClient(slot.AgentHostName)
+	if err != nil {
+		logrus.Errorf("Exec into task %s err: %s", task.ID, err.Error())
+		(&sseWriter{w: response, flusher: flusher, event: "error"}).Write([]byte(err.Error()))
+		return
+	}
+
+	exitCode, err := client.Run(request.Request.Context(), container, param.Cmd, stdout, stderr)
+	if err != nil {
+		logrus.Errorf("Exec into task %s err: %s", task.ID, err.Error())
+		(&sseWriter{w: response, flusher: flusher, event: "error"}).Write([]byte(err.Error()))
+		return
+	}
+
+	data, _ := json.Marshal(types.ExecResult{ExitCode: exitCode})
+	(&sseWriter{w: response, flusher: flusher, event: "exit"}).Write(data)
+}
+
+// exec is enabled only with tls to docker daemon configured
+func (api *AppService) dockerClient(host string) (*dockerexec.Client, error) {
+	return dockerexec.NewTLSClient(host, api.dockerAPIPort, api.dockerTLSCaCert, api.dockerTLSCert, api.dockerTLSKey)
+}
+
+// each write is sent as a single server-sent event, lines in it as data
+// fields, which the client joins by newline again
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	event   string
+}
+
+func (s *sseWriter) Write(p []byte) (int, error) {
+	buf := "event: " + s.event + "\n"
+	for _, line := range strings.Split(string(p), "\n") {
+		buf += "data: " + strings.TrimSuffix(line, "\r") + "\n"
+	}
+	buf += "\n"
+
+	if _, err := s.w.Write([]byte(buf)); err != nil {
+		return 0, err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+
+	return len(p), nil
+}
+
 func (api *AppService) UpdateAppTaskWeight(request *restful.Request, response *restful.Response) {
 	var param types.UpdateWeightParam
 
@@ -614,6 +1150,7 @@ func FormApp(app *state.App) *types.App {
 		State:            app.StateMachine.ReadableState(),
 		CurrentVersion:   app.CurrentVersion,
 		ProposedVersion:  app.ProposedVersion,
+		Rollbacks:        app.Rollbacks,
 		Labels:           version.Labels,
 		Env:              version.Env,
 		Constraints:      version.Constraints,
@@ -665,33 +1202,11 @@ func FilterTasksWithHistoryFromApp(app *state.App) []*types.Task {
 	for _, slot := range app.GetSlots() {
 		task := FormTask(slot)
 
-		if len(slot.TaskHistory) > 0 {
-			for _, v := range slot.TaskHistory {
-				staleTask := &types.TaskHistory{
-					ID:            v.ID,
-					AppID:         app.ID,
-					State:         v.State,
-					Reason:        v.Reason,
-					Message:       v.Message,
-					OfferID:       v.OfferID,
-					AgentID:       v.AgentID,
-					AgentHostname: v.AgentHostName,
-
-					Stderr: v.Stderr,
-					Stdout: v.Stdout,
-
-					ArchivedAt: v.ArchivedAt,
-				}
-				if v.Version != nil {
-					staleTask.VersionID = v.Version.ID
-					staleTask.AppVersion = v.Version.AppVersion
-					staleTask.CPU = v.Version.CPUs
-					staleTask.Mem = v.Version.Mem
-					staleTask.Disk = v.Version.Disk
-				}
+		for _, v := range slot.TaskHistory {
+			staleTask := FormTaskHistory(v)
+			staleTask.AppID = app.ID
 
-				task.History = append(task.History, staleTask)
-			}
+			task.History = append(task.History, staleTask)
 		}
 
 		tasks = append(tasks, task)
@@ -725,27 +1240,35 @@ func GetTaskFromApp(app *state.App, task_index int) (*types.Task, error) {
 }
 
 func FormTaskHistory(v *state.Task) *types.TaskHistory {
-	return &types.TaskHistory{
+	history := &types.TaskHistory{
 		ID:            v.ID,
 		State:         v.State,
 		Reason:        v.Reason,
 		Message:       v.Message,
+		Source:        v.Source,
 		OfferID:       v.OfferID,
 		AgentID:       v.AgentID,
 		AgentHostname: v.AgentHostName,
-		VersionID:     v.Version.ID,
 		ContainerId:   v.ContainerId,
 		ContainerName: v.ContainerName,
 
-		CPU:  v.Version.CPUs,
-		Mem:  v.Version.Mem,
-		Disk: v.Version.Disk,
-
 		Stderr: v.Stderr,
 		Stdout: v.Stdout,
 
+		Created:    v.Created,
 		ArchivedAt: v.ArchivedAt,
 	}
+
+	// version might be gone after manager failover
+	if v.Version != nil {
+		history.VersionID = v.Version.ID
+		history.AppVersion = v.Version.AppVersion
+		history.CPU = v.Version.CPUs
+		history.Mem = v.Version.Mem
+		history.Disk = v.Version.Disk
+	}
+
+	return history
 }
 
 func FormTask(slot *state.Slot) *types.Task {
@@ -756,6 +1279,7 @@ func FormTask(slot *state.Slot) *types.Task {
 		VersionID:     slot.Version.ID,
 		AppVersion:    slot.Version.AppVersion,
 		Healthy:       slot.Healthy(),
+		Ready:         slot.Ready(),
 		Status:        string(slot.State),
 		OfferID:       slot.OfferID,
 		AgentID:       slot.AgentID,
@@ -771,6 +1295,8 @@ func FormTask(slot *state.Slot) *types.Task {
 		ContainerId:   slot.CurrentTask.ContainerId,
 		ContainerName: slot.CurrentTask.ContainerName,
 		Weight:        slot.GetWeight(),
+		Standby:       slot.Standby(),
+		Health:        slot.Health(),
 	}
 	return task
 }
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/config"
+	"github.com/Dataman-Cloud/swan/src/manager/apiserver"
+	"github.com/Dataman-Cloud/swan/src/manager/apiserver/metrics"
+	"github.com/Dataman-Cloud/swan/src/manager/scheduler"
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/emicklei/go-restful"
+)
+
+type JobService struct {
+	Scheduler *scheduler.Scheduler
+	apiServer *apiserver.ApiServer
+}
+
+func NewAndInstallJobService(apiServer *apiserver.ApiServer, eng *scheduler.Scheduler) {
+	jobService := &JobService{
+		Scheduler: eng,
+		apiServer: apiServer,
+	}
+	apiserver.Install(apiServer, jobService)
+}
+
+func (api *JobService) Register(container *restful.Container) {
+	ws := new(restful.WebService)
+	ws.
+		ApiVersion(config.API_PREFIX).
+		Path(config.API_PREFIX + "/jobs").
+		Doc("Job management").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/").To(metrics.InstrumentRouteFunc("GET", "Jobs", api.ListJobs)).
+		// docs
+		Doc("List Jobs").
+		Operation("listJobs").
+		Returns(200, "OK", []types.Job{}))
+	ws.Route(ws.POST("/").To(metrics.InstrumentRouteFunc("POST", "Job", api.CreateJob)).
+		// docs
+		Doc("Create Job").
+		Operation("createJob").
+		Returns(201, "OK", types.Job{}).
+		Returns(400, "BadRequest", nil).
+		Reads(types.JobSpec{}).
+		Writes(types.Job{}))
+	ws.Route(ws.GET("/{job_id}").To(metrics.InstrumentRouteFunc("GET", "Job", api.GetJob)).
+		// docs
+		Doc("Get a Job").
+		Operation("getJob").
+		Param(ws.PathParameter("job_id", "identifier of the job").DataType("string")).
+		Returns(200, "OK", types.Job{}).
+		Returns(404, "NotFound", nil).
+		Writes(types.Job{}))
+	ws.Route(ws.PUT("/{job_id}").To(metrics.InstrumentRouteFunc("PUT", "Job", api.UpdateJob)).
+		// docs
+		Doc("Update Job").
+		Operation("updateJob").
+		Returns(200, "OK", types.Job{}).
+		Returns(400, "BadRequest", nil).
+		Reads(types.JobSpec{}).
+		Writes(types.Job{}).
+		Param(ws.PathParameter("job_id", "identifier of the job").DataType("string")))
+	ws.Route(ws.DELETE("/{job_id}").To(metrics.InstrumentRouteFunc("DELETE", "Job", api.DeleteJob)).
+		// docs
+		Doc("Delete Job").
+		Operation("deleteJob").
+		Returns(204, "OK", nil).
+		Returns(404, "NotFound", nil).
+		Param(ws.PathParameter("job_id", "identifier of the job").DataType("string")))
+	ws.Route(ws.GET("/{job_id}/runs").To(metrics.InstrumentRouteFunc("GET", "JobRuns", api.ListJobRuns)).
+		// docs
+		Doc("List Runs of Job").
+		Operation("listJobRuns").
+		Param(ws.PathParameter("job_id", "identifier of the job").DataType("string")).
+		Returns(200, "OK", []types.JobRun{}).
+		Returns(404, "NotFound", nil))
+	ws.Route(ws.POST("/{job_id}/runs").To(metrics.InstrumentRouteFunc("POST", "JobRun", api.StartJobRun)).
+		// docs
+		Doc("Start a Run of Job").
+		Operation("startJobRun").
+		Param(ws.PathParameter("job_id", "identifier of the job").DataType("string")).
+		Returns(201, "OK", types.JobRun{}).
+		Returns(400, "BadRequest", nil).
+		Writes(types.JobRun{}))
+	ws.Route(ws.GET("/{job_id}/runs/{run_id}").To(metrics.InstrumentRouteFunc("GET", "JobRun", api.GetJobRun)).
+		// docs
+		Doc("Get a Run of Job").
+		Operation("getJobRun").
+		Param(ws.PathParameter("job_id", "identifier of the job").DataType("string")).
+		Param(ws.PathParameter("run_id", "identifier of the run").DataType("string")).
+		Returns(200, "OK", types.JobRun{}).
+		Returns(404, "NotFound", nil).
+		Writes(types.JobRun{}))
+	ws.Route(ws.DELETE("/{job_id}/runs/{run_id}").To(metrics.InstrumentRouteFunc("DELETE", "JobRun", api.KillJobRun)).
+		// docs
+		Doc("Kill a Run of Job").
+		Operation("killJobRun").
+		Param(ws.PathParameter("job_id", "identifier of the job").DataType("string")).
+		Param(ws.PathParameter("run_id", "identifier of the run").DataType("string")).
+		Returns(204, "OK", nil).
+		Returns(400, "BadRequest", nil))
+
+	container.Add(ws)
+}
+
+func (api *JobService) ListJobs(request *restful.Request, response *restful.Response) {
+	jobsRet := make([]*types.Job, 0)
+	for _, job := range api.Scheduler.ListJobs() {
+		jobsRet = append(jobsRet, api.formJob(job))
+	}
+
+	response.WriteEntity(jobsRet)
+}
+
+func (api *JobService) CreateJob(request *restful.Request, response *restful.Response) {
+	var spec types.JobSpec
+
+	err := request.ReadEntity(&spec)
+	if err != nil {
+		logrus.Errorf("Create job error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := api.Scheduler.CreateJob(&spec)
+	if err != nil {
+		logrus.Errorf("Create job error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteHeaderAndEntity(http.StatusCreated, api.formJob(job))
+}
+
+func (api *JobService) GetJob(request *restful.Request, response *restful.Response) {
+	job, err := api.Scheduler.InspectJob(request.PathParameter("job_id"))
+	if err != nil {
+		logrus.Debugf("Get job error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteEntity(api.formJob(job))
+}
+
+func (api *JobService) UpdateJob(request *restful.Request, response *restful.Response) {
+	var spec types.JobSpec
+
+	err := request.ReadEntity(&spec)
+	if err != nil {
+		logrus.Errorf("Update job error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := api.Scheduler.UpdateJob(request.PathParameter("job_id"), &spec)
+	if err != nil {
+		logrus.Errorf("Update job error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteEntity(api.formJob(job))
+}
+
+func (api *JobService) DeleteJob(request *restful.Request, response *restful.Response) {
+	err := api.Scheduler.DeleteJob(request.PathParameter("job_id"))
+	if err != nil {
+		logrus.Errorf("Delete job error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func (api *JobService) ListJobRuns(request *restful.Request, response *restful.Response) {
+	jobId := request.PathParameter("job_id")
+
+	runs, err := api.Scheduler.ListJobRuns(jobId)
+	if err != nil {
+		logrus.Debugf("List job runs error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	runsRet := make([]*types.JobRun, 0)
+	for _, run := range runs {
+		runsRet = append(runsRet, formJobRun(jobId, run))
+	}
+
+	response.WriteEntity(runsRet)
+}
+
+func (api *JobService) StartJobRun(request *restful.Request, response *restful.Response) {
+	jobId := request.PathParameter("job_id")
+
+	run, err := api.Scheduler.StartJobRun(jobId)
+	if err != nil {
+		logrus.Errorf("Start job run error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteHeaderAndEntity(http.StatusCreated, formJobRun(jobId, run))
+}
+
+func (api *JobService) GetJobRun(request *restful.Request, response *restful.Response) {
+	jobId := request.PathParameter("job_id")
+
+	run, err := api.Scheduler.InspectJobRun(jobId, request.PathParameter("run_id"))
+	if err != nil {
+		logrus.Debugf("Get job run error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteEntity(formJobRun(jobId, run))
+}
+
+func (api *JobService) KillJobRun(request *restful.Request, response *restful.Response) {
+	err := api.Scheduler.KillJobRun(request.PathParameter("job_id"), request.PathParameter("run_id"))
+	if err != nil {
+		logrus.Errorf("Kill job run error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func (api *JobService) formJob(job *store.Job) *types.Job {
+	jobRet := &types.Job{
+		ID:          job.ID,
+		Name:        job.Name,
+		RunAs:       job.RunAs,
+		ClusterID:   job.ClusterID,
+		Schedule:    job.Schedule,
+		Parallelism: job.Parallelism,
+		Retries:     job.Retries,
+		NextRun:     api.Scheduler.NextJobRun(job),
+		Created:     time.Unix(0, job.CreatedAt),
+		Updated:     time.Unix(0, job.UpdatedAt),
+	}
+
+	if job.Version != nil {
+		jobRet.Task = state.VersionFromRaft(job.Version)
+	}
+
+	if run := api.Scheduler.ActiveJobRun(job); run != nil {
+		jobRet.ActiveRun = run.ID
+	}
+
+	return jobRet
+}
+
+func formJobRun(jobId string, run *store.JobRun) *types.JobRun {
+	runRet := &types.JobRun{
+		ID:      run.ID,
+		JobID:   jobId,
+		State:   run.State,
+		Trigger: run.Trigger,
+		Message: run.Message,
+		Tasks:   make([]*types.JobRunTask, 0),
+		Started: time.Unix(0, run.StartedAt),
+	}
+
+	if run.FinishedAt > 0 {
+		finished := time.Unix(0, run.FinishedAt)
+		runRet.Finished = &finished
+	}
+
+	for _, task := range run.Tasks {
+		runRet.Tasks = append(runRet.Tasks, &types.JobRunTask{
+			Index:         int(task.Index),
+			TaskID:        task.TaskID,
+			State:         task.State,
+			Attempts:      int(task.Attempts),
+			AgentHostName: task.AgentHostName,
+			Reason:        task.Reason,
+			Message:       task.Message,
+		})
+	}
+
+	return runRet
+}
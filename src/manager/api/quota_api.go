@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/config"
+	"github.com/Dataman-Cloud/swan/src/manager/apiserver"
+	"github.com/Dataman-Cloud/swan/src/manager/apiserver/metrics"
+	"github.com/Dataman-Cloud/swan/src/manager/scheduler"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/emicklei/go-restful"
+)
+
+type QuotaService struct {
+	Scheduler *scheduler.Scheduler
+	apiServer *apiserver.ApiServer
+}
+
+func NewAndInstallQuotaService(apiServer *apiserver.ApiServer, eng *scheduler.Scheduler) {
+	quotaService := &QuotaService{
+		Scheduler: eng,
+		apiServer: apiServer,
+	}
+	apiserver.Install(apiServer, quotaService)
+}
+
+func (api *QuotaService) Register(container *restful.Container) {
+	ws := new(restful.WebService)
+	ws.
+		ApiVersion(config.API_PREFIX).
+		Path(config.API_PREFIX + "/quotas").
+		Doc("Resource quota management per runAs user").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/").To(metrics.InstrumentRouteFunc("GET", "Quotas", api.ListQuotas)).
+		// docs
+		Doc("List Quotas").
+		Operation("listQuotas").
+		Returns(200, "OK", []types.Quota{}))
+	ws.Route(ws.POST("/").To(metrics.InstrumentRouteFunc("POST", "Quota", api.CreateQuota)).
+		// docs
+		Doc("Create Quota").
+		Operation("createQuota").
+		Returns(201, "OK", types.Quota{}).
+		Returns(400, "BadRequest", nil).
+		Reads(types.Quota{}).
+		Writes(types.Quota{}))
+	ws.Route(ws.GET("/{run_as}").To(metrics.InstrumentRouteFunc("GET", "Quota", api.GetQuota)).
+		// docs
+		Doc("Get a Quota with usage").
+		Operation("getQuota").
+		Param(ws.PathParameter("run_as", "runAs user of the quota").DataType("string")).
+		Returns(200, "OK", types.Quota{}).
+		Returns(404, "NotFound", nil).
+		Writes(types.Quota{}))
+	ws.Route(ws.PUT("/{run_as}").To(metrics.InstrumentRouteFunc("PUT", "Quota", api.UpdateQuota)).
+		// docs
+		Doc("Update Quota").
+		Operation("updateQuota").
+		Returns(200, "OK", types.Quota{}).
+		Returns(400, "BadRequest", nil).
+		Reads(types.Quota{}).
+		Writes(types.Quota{}).
+		Param(ws.PathParameter("run_as", "runAs user of the quota").DataType("string")))
+	ws.Route(ws.DELETE("/{run_as}").To(metrics.InstrumentRouteFunc("DELETE", "Quota", api.DeleteQuota)).
+		// docs
+		Doc("Delete Quota").
+		Operation("deleteQuota").
+		Returns(204, "OK", nil).
+		Returns(404, "NotFound", nil).
+		Param(ws.PathParameter("run_as", "runAs user of the quota").DataType("string")))
+
+	container.Add(ws)
+}
+
+func (api *QuotaService) ListQuotas(request *restful.Request, response *restful.Response) {
+	quotasRet := make([]*types.Quota, 0)
+	for _, quota := range api.Scheduler.ListQuotas() {
+		quotasRet = append(quotasRet, formQuota(api.Scheduler, quota))
+	}
+
+	response.WriteEntity(quotasRet)
+}
+
+func (api *QuotaService) CreateQuota(request *restful.Request, response *restful.Response) {
+	var spec types.Quota
+
+	err := request.ReadEntity(&spec)
+	if err != nil {
+		logrus.Errorf("Create quota error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	quota, err := api.Scheduler.CreateQuota(&spec)
+	if err != nil {
+		logrus.Errorf("Create quota error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteHeaderAndEntity(http.StatusCreated, formQuota(api.Scheduler, quota))
+}
+
+func (api *QuotaService) GetQuota(request *restful.Request, response *restful.Response) {
+	quota, err := api.Scheduler.InspectQuota(request.PathParameter("run_as"))
+	if err != nil {
+		logrus.Debugf("Get quota error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteEntity(formQuota(api.Scheduler, quota))
+}
+
+func (api *QuotaService) UpdateQuota(request *restful.Request, response *restful.Response) {
+	var spec types.Quota
+
+	err := request.ReadEntity(&spec)
+	if err != nil {
+		logrus.Errorf("Update quota error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	quota, err := api.Scheduler.UpdateQuota(request.PathParameter("run_as"), &spec)
+	if err != nil {
+		logrus.Errorf("Update quota error: %s", err.Error())
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	response.WriteEntity(formQuota(api.Scheduler, quota))
+}
+
+func (api *QuotaService) DeleteQuota(request *restful.Request, response *restful.Response) {
+	err := api.Scheduler.DeleteQuota(request.PathParameter("run_as"))
+	if err != nil {
+		logrus.Errorf("Delete quota error: %s", err.Error())
+		response.WriteError(http.StatusNotFound, err)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func formQuota(sched *scheduler.Scheduler, quota *store.Quota) *types.Quota {
+	return &types.Quota{
+		RunAs:     quota.RunAs,
+		CPUs:      quota.Cpus,
+		Mem:       quota.Mem,
+		Disk:      quota.Disk,
+		Instances: quota.Instances,
+		Used:      sched.QuotaUsage(quota.RunAs, ""),
+		Created:   time.Unix(0, quota.CreatedAt),
+		Updated:   time.Unix(0, quota.UpdatedAt),
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/Dataman-Cloud/swan/src/manager/apiserver/metrics"
 	"github.com/Dataman-Cloud/swan/src/manager/connector"
 	"github.com/Dataman-Cloud/swan/src/manager/scheduler"
+	mstate "github.com/Dataman-Cloud/swan/src/manager/state"
 	"github.com/Dataman-Cloud/swan/src/types"
 	"github.com/andygrunwald/megos"
 	"github.com/emicklei/go-restful"
@@ -66,6 +67,13 @@ func (api *StatsService) Stats(request *restful.Request, response *restful.Respo
 		}
 	}
 
+	stats.PendingOfferSlots = mstate.OfferAllocatorInstance().PendingOfferDepths()
+
+	stats.Quotas = make(map[string]*types.Quota)
+	for _, quota := range api.Scheduler.ListQuotas() {
+		stats.Quotas[quota.RunAs] = formQuota(api.Scheduler, quota)
+	}
+
 	master := strings.Split(connector.Instance().MesosLeader, "@")[1]
 	node, _ := url.Parse(fmt.Sprintf("http://%s", master))
 	state, _ := megos.NewClient([]*url.URL{node}, nil).GetStateFromCluster()
@@ -46,6 +46,8 @@ type Connector struct {
 
 	StreamCtx       context.Context
 	StreamCancelFun context.CancelFunc
+
+	offerFilters offerFilters
 }
 
 func Instance() *Connector {
@@ -75,6 +77,11 @@ func Init(user string, mesosZkPath *url.URL) {
 				EventChan:     make(chan *event.MesosEvent, 1024),
 				ErrorChan:     make(chan error, 1024),
 				FrameworkInfo: info,
+				offerFilters: offerFilters{
+					refuseSeconds:    DEFAULT_OFFER_REFUSE_SECONDS,
+					maxRefuseSeconds: DEFAULT_OFFER_MAX_REFUSE_SECONDS,
+					declines:         make(map[string]int),
+				},
 			}
 		})
 }
@@ -0,0 +1,135 @@
+package connector
+
+import (
+	"math"
+	"sync"
+
+	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
+	"github.com/Dataman-Cloud/swan/src/mesosproto/sched"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	DEFAULT_OFFER_REFUSE_SECONDS     = 5
+	DEFAULT_OFFER_MAX_REFUSE_SECONDS = 60
+)
+
+// refuse seconds of declined offers double for agents whose offers keep
+// being declined, up to maxRefuseSeconds. offers are suppressed while nothing
+// pending, both are undone by revive once new slots are queued
+type offerFilters struct {
+	refuseSeconds    float64
+	maxRefuseSeconds float64
+
+	// consecutive declines by agent id
+	declines   map[string]int
+	suppressed bool
+	sync.Mutex
+}
+
+func (s *Connector) SetOfferFilters(refuseSeconds, maxRefuseSeconds float64) {
+	s.offerFilters.Lock()
+	defer s.offerFilters.Unlock()
+
+	if refuseSeconds <= 0 {
+		refuseSeconds = DEFAULT_OFFER_REFUSE_SECONDS
+	}
+	if maxRefuseSeconds < refuseSeconds {
+		maxRefuseSeconds = refuseSeconds
+	}
+
+	s.offerFilters.refuseSeconds = refuseSeconds
+	s.offerFilters.maxRefuseSeconds = maxRefuseSeconds
+}
+
+// filters of declining an offer of the agent
+func (s *Connector) DeclineFilters(agentID string) *mesos.Filters {
+	f := &s.offerFilters
+	f.Lock()
+	defer f.Unlock()
+
+	seconds := refuseSeconds(f.refuseSeconds, f.maxRefuseSeconds, f.declines[agentID])
+	f.declines[agentID] += 1
+
+	return &mesos.Filters{RefuseSeconds: proto.Float64(seconds)}
+}
+
+// filters of unused resources of an accepted offer of the agent
+func (s *Connector) LaunchFilters(agentID string) *mesos.Filters {
+	f := &s.offerFilters
+	f.Lock()
+	defer f.Unlock()
+
+	delete(f.declines, agentID)
+
+	return &mesos.Filters{RefuseSeconds: proto.Float64(f.refuseSeconds)}
+}
+
+func refuseSeconds(base, max float64, declines int) float64 {
+	return math.Min(base*math.Pow(2, float64(declines)), max)
+}
+
+// stop receiving offers, called while nothing pending
+func (s *Connector) SuppressOffers() {
+	f := &s.offerFilters
+	f.Lock()
+	if f.suppressed {
+		f.Unlock()
+		return
+	}
+	f.suppressed = true
+	f.Unlock()
+
+	logrus.Info("nothing pending, suppress offers")
+
+	s.SendCall(&sched.Call{
+		FrameworkId: s.FrameworkInfo.GetId(),
+		Type:        sched.Call_SUPPRESS.Enum(),
+	})
+}
+
+// receive offers again with all filters cleared, called once new slots are
+// queued. nothing is sent unless suppressed or some agents filtered longer
+// than refuseSeconds
+func (s *Connector) ReviveOffers() {
+	f := &s.offerFilters
+	f.Lock()
+	revive := f.suppressed
+	for _, declines := range f.declines {
+		if declines > 1 {
+			revive = true
+		}
+	}
+	if !revive {
+		f.Unlock()
+		return
+	}
+	f.suppressed = false
+	f.declines = make(map[string]int)
+	f.Unlock()
+
+	logrus.Info("new slots pending, revive offers")
+
+	s.SendCall(&sched.Call{
+		FrameworkId: s.FrameworkInfo.GetId(),
+		Type:        sched.Call_REVIVE.Enum(),
+	})
+}
+
+func (s *Connector) OffersSuppressed() bool {
+	s.offerFilters.Lock()
+	defer s.offerFilters.Unlock()
+
+	return s.offerFilters.suppressed
+}
+
+// suppression and filters might be reset by mesos master on subscribing, start over
+func (s *Connector) ResetOfferFilters() {
+	s.offerFilters.Lock()
+	defer s.offerFilters.Unlock()
+
+	s.offerFilters.suppressed = false
+	s.offerFilters.declines = make(map[string]int)
+}
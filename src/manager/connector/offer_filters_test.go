@@ -0,0 +1,32 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfferFilters(t *testing.T) {
+	s := &Connector{offerFilters: offerFilters{declines: make(map[string]int)}}
+	s.SetOfferFilters(5, 30)
+
+	seconds := make([]float64, 0)
+	for i := 0; i < 5; i++ {
+		seconds = append(seconds, s.DeclineFilters("agent-a").GetRefuseSeconds())
+	}
+	assert.Equal(t, []float64{5, 10, 20, 30, 30}, seconds)
+	assert.Equal(t, float64(5), s.DeclineFilters("agent-b").GetRefuseSeconds())
+
+	// launching on the agent starts over
+	assert.Equal(t, float64(5), s.LaunchFilters("agent-a").GetRefuseSeconds())
+	assert.Equal(t, float64(5), s.DeclineFilters("agent-a").GetRefuseSeconds())
+
+	// nothing to revive while offers neither suppressed nor filtered longer
+	s.ReviveOffers()
+	assert.False(t, s.OffersSuppressed())
+	assert.Len(t, s.offerFilters.declines, 2)
+
+	s.SetOfferFilters(0, 0)
+	assert.Equal(t, float64(DEFAULT_OFFER_REFUSE_SECONDS), s.offerFilters.refuseSeconds)
+	assert.Equal(t, float64(DEFAULT_OFFER_REFUSE_SECONDS), s.offerFilters.maxRefuseSeconds)
+}
@@ -0,0 +1,217 @@
+package dockerexec
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// header of each frame in multiplexed stdout and stderr of docker
+	FRAME_HEADER_SIZE = 8
+
+	STREAM_STDOUT = 1
+	STREAM_STDERR = 2
+)
+
+var (
+	ErrContainerNotFound = errors.New("container not found on agent")
+)
+
+// exec of docker engine api, the docker daemon of the agent is expected to
+// listen on tcp, with tls verifying client certs
+type Client struct {
+	Addr string
+
+	scheme string
+	client *http.Client
+}
+
+type createExecRequest struct {
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Cmd          []string `json:"Cmd"`
+}
+
+type startExecRequest struct {
+	Detach bool `json:"Detach"`
+	Tty    bool `json:"Tty"`
+}
+
+type execInspect struct {
+	Running  bool `json:"Running"`
+	ExitCode int  `json:"ExitCode"`
+}
+
+func NewClient(host string, port int) *Client {
+	return &Client{
+		Addr:   net.JoinHostPort(host, strconv.Itoa(port)),
+		scheme: "http",
+		client: &http.Client{
+			Transport: &http.Transport{
+				Dial: (&net.Dialer{Timeout: 10 * time.Second}).Dial,
+			},
+		},
+	}
+}
+
+// the docker daemon is verified by tlsCaCert, and swan by tlsCert on it
+func NewTLSClient(host string, port int, tlsCaCert, tlsCert, tlsKey string) (*Client, error) {
+	caCert, err := ioutil.ReadFile(tlsCaCert)
+	if err != nil {
+		return nil, err
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificate found in %s", tlsCaCert)
+	}
+
+	cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient(host, port)
+	client.scheme = "https"
+	client.client.Transport = &http.Transport{
+		Dial:                (&net.Dialer{Timeout: 10 * time.Second}).Dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      caCertPool,
+		},
+	}
+
+	return client, nil
+}
+
+// run cmd in the container, output is copied to stdout and stderr while
+// running, until the command exits or ctx is done
+func (c *Client) Run(ctx context.Context, container string, cmd []string, stdout, stderr io.Writer) (int, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+
+	path := "/containers/" + url.PathEscape(container) + "/exec"
+	req := createExecRequest{AttachStdout: true, AttachStderr: true, Cmd: cmd}
+	if err := c.post(ctx, path, req, &created); err != nil {
+		return -1, err
+	}
+
+	resp, err := c.do(ctx, "POST", "/exec/"+created.ID+"/start", startExecRequest{})
+	if err != nil {
+		return -1, err
+	}
+
+	err = Demultiplex(resp.Body, stdout, stderr)
+	resp.Body.Close()
+	if err != nil {
+		return -1, err
+	}
+
+	var inspect execInspect
+	if err := c.get(ctx, "/exec/"+created.ID+"/json", &inspect); err != nil {
+		return -1, err
+	}
+
+	return inspect.ExitCode, nil
+}
+
+// split the multiplexed stream of docker into stdout and stderr
+func Demultiplex(r io.Reader, stdout, stderr io.Writer) error {
+	header := make([]byte, FRAME_HEADER_SIZE)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var w io.Writer
+		switch header[0] {
+		case STREAM_STDOUT:
+			w = stdout
+		case STREAM_STDERR:
+			w = stderr
+		default:
+			return fmt.Errorf("unknown stream %d in exec output", header[0])
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(w, r, size); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) post(ctx context.Context, path string, body, result interface{}) error {
+	resp, err := c.do(ctx, "POST", path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (c *Client) get(ctx context.Context, path string, result interface{}) error {
+	resp, err := c.do(ctx, "GET", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.scheme+"://"+c.Addr+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrContainerNotFound
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s on %s got status %d: %s", method, path, c.Addr, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return resp, nil
+}
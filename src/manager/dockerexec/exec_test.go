@@ -0,0 +1,160 @@
+package dockerexec
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func frame(stream byte, data string) []byte {
+	header := make([]byte, FRAME_HEADER_SIZE)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	return append(header, data...)
+}
+
+func TestDemultiplex(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(frame(STREAM_STDOUT, "hello\n"))
+	stream.Write(frame(STREAM_STDERR, "oops\n"))
+	stream.Write(frame(STREAM_STDOUT, "world\n"))
+
+	var stdout, stderr bytes.Buffer
+	assert.Nil(t, Demultiplex(&stream, &stdout, &stderr))
+	assert.Equal(t, "hello\nworld\n", stdout.String())
+	assert.Equal(t, "oops\n", stderr.String())
+
+	assert.NotNil(t, Demultiplex(bytes.NewReader(frame(5, "x")), &stdout, &stderr))
+	assert.NotNil(t, Demultiplex(bytes.NewReader(frame(STREAM_STDOUT, "x")[:10]), &stdout, &stderr))
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/abc/exec":
+			var req createExecRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, []string{"ls", "/"}, req.Cmd)
+			w.Write([]byte(`{"Id":"e1"}`))
+		case "/exec/e1/start":
+			w.Write(frame(STREAM_STDOUT, "bin\n"))
+		case "/exec/e1/json":
+			w.Write([]byte(`{"Running":false,"ExitCode":2}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	p, _ := strconv.Atoi(port)
+	client := NewClient(host, p)
+
+	var stdout, stderr bytes.Buffer
+	code, err := client.Run(context.Background(), "abc", []string{"ls", "/"}, &stdout, &stderr)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, code)
+	assert.Equal(t, "bin\n", stdout.String())
+
+	_, err = client.Run(context.Background(), "missing", []string{"ls"}, &stdout, &stderr)
+	assert.Equal(t, ErrContainerNotFound, err)
+}
+
+// self-signed cert for 127.0.0.1, used as ca, server and client cert at once
+func writeCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "swan"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func TestRunTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockerexec")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeCert(t, dir)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/abc/exec":
+			w.Write([]byte(`{"Id":"e1"}`))
+		case "/exec/e1/start":
+			w.Write(frame(STREAM_STDOUT, "bin\n"))
+		case "/exec/e1/json":
+			w.Write([]byte(`{"Running":false,"ExitCode":0}`))
+		}
+	}))
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	assert.Nil(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.Nil(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	p, _ := strconv.Atoi(port)
+
+	var stdout, stderr bytes.Buffer
+	_, err = NewClient(host, p).Run(context.Background(), "abc", []string{"ls"}, &stdout, &stderr)
+	assert.NotNil(t, err)
+
+	client, err := NewTLSClient(host, p, certFile, certFile, keyFile)
+	assert.Nil(t, err)
+	code, err := client.Run(context.Background(), "abc", []string{"ls"}, &stdout, &stderr)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "bin\n", stdout.String())
+
+	_, err = NewTLSClient(host, p, keyFile, certFile, keyFile)
+	assert.NotNil(t, err)
+}
@@ -10,6 +10,9 @@ const (
 	EVENT_TYPE_MESOS_MESSAGE    = "mesos_message"
 	EVENT_TYPE_MESOS_ERROR      = "mesos_error"
 
+	EVENT_TYPE_MESOS_INVERSE_OFFERS        = "mesos_inverse_offers"
+	EVENT_TYPE_MESOS_RESCIND_INVERSE_OFFER = "mesos_rescind_inverse_offer"
+
 	EVENT_TYPE_USER_INVALID_APPS = "user_invalidapps"
 )
 
@@ -29,6 +29,10 @@ func (me *MesosEvent) GetEventType() string {
 		return EVENT_TYPE_MESOS_MESSAGE
 	case sched.Event_ERROR:
 		return EVENT_TYPE_MESOS_ERROR
+	case sched.Event_INVERSE_OFFERS:
+		return EVENT_TYPE_MESOS_INVERSE_OFFERS
+	case sched.Event_RESCIND_INVERSE_OFFER:
+		return EVENT_TYPE_MESOS_RESCIND_INVERSE_OFFER
 	default:
 		panic("not known event type")
 	}
@@ -88,3 +88,21 @@ func TestMesosEventGetEvent(t *testing.T) {
 
 	assert.Equal(t, me.GetEvent(), e)
 }
+
+func TestMesosEventGetEventTypeInverseOffers(t *testing.T) {
+	me := &MesosEvent{
+		EventType: sched.Event_INVERSE_OFFERS,
+		Event:     &sched.Event{},
+	}
+
+	assert.Equal(t, me.GetEventType(), EVENT_TYPE_MESOS_INVERSE_OFFERS)
+}
+
+func TestMesosEventGetEventTypeRescindInverseOffer(t *testing.T) {
+	me := &MesosEvent{
+		EventType: sched.Event_RESCIND_INVERSE_OFFER,
+		Event:     &sched.Event{},
+	}
+
+	assert.Equal(t, me.GetEventType(), EVENT_TYPE_MESOS_RESCIND_INVERSE_OFFER)
+}
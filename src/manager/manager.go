@@ -74,7 +74,10 @@ func New(managerConf config.ManagerConfig) (*Manager, error) {
 
 	sched := scheduler.NewScheduler(managerConf)
 	route := apiserver.NewApiServer(managerConf.ListenAddr)
-	api.NewAndInstallAppService(route, sched)
+	api.NewAndInstallAppService(route, sched, managerConf)
+	api.NewAndInstallGroupService(route, sched)
+	api.NewAndInstallJobService(route, sched)
+	api.NewAndInstallQuotaService(route, sched)
 	api.NewAndInstallStatsService(route, sched)
 	api.NewAndInstallEventsService(route, sched)
 	api.NewAndInstallHealthyService(route)
@@ -0,0 +1,203 @@
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/andygrunwald/megos"
+)
+
+const (
+	// max bytes read from sandbox by a single request
+	READ_CHUNK_SIZE = 64 * 1024
+	// max bytes scanned backwards for tail lines
+	TAIL_MAX_BYTES = 1024 * 1024
+)
+
+var (
+	ErrSandboxNotFound = errors.New("sandbox not found on agent")
+)
+
+// sandbox of a task on its mesos agent, files in it are read through the
+// files api of the agent
+type Sandbox struct {
+	Agent     string
+	Directory string
+
+	client *http.Client
+}
+
+type fileChunk struct {
+	Data   string `json:"data"`
+	Offset int64  `json:"offset"`
+}
+
+// the agent is resolved by mesos leader, and the sandbox by executor of the
+// task, which might be completed already
+func Locate(leader, agentID, taskID string) (*Sandbox, error) {
+	client := megos.NewClient(nil, &http.Client{Timeout: 10 * time.Second})
+
+	leaderPid, err := client.ParsePidInformation(leader)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := client.GetSlavesFromPid(leaderPid)
+	if err != nil {
+		return nil, err
+	}
+
+	slave, err := client.GetSlaveByID(state.Slaves, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	agentPid, err := client.ParsePidInformation(slave.PID)
+	if err != nil {
+		return nil, err
+	}
+
+	agentState, err := client.GetStateFromPid(agentPid)
+	if err != nil {
+		return nil, err
+	}
+
+	directory := executorDirectory(agentState, taskID)
+	if directory == "" {
+		return nil, ErrSandboxNotFound
+	}
+
+	return &Sandbox{
+		Agent:     agentPid.Host + ":" + strconv.Itoa(agentPid.Port),
+		Directory: directory,
+		client:    client.Http,
+	}, nil
+}
+
+func executorDirectory(state *megos.State, taskID string) string {
+	frameworks := append(state.Frameworks, state.CompletedFrameworks...)
+	for _, framework := range frameworks {
+		executors := append(framework.Executors, framework.CompletedExecutors...)
+		for _, executor := range executors {
+			if executor.ID == taskID {
+				return executor.Directory
+			}
+
+			tasks := append(executor.Tasks, executor.CompletedTasks...)
+			for _, task := range append(tasks, executor.QueuedTasks...) {
+				if task.ID == taskID {
+					return executor.Directory
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// size of the file in sandbox
+func (s *Sandbox) Size(file string) (int64, error) {
+	chunk, err := s.read(file, -1, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	return chunk.Offset, nil
+}
+
+// at most length bytes from offset, less or empty at the end of file
+func (s *Sandbox) Read(file string, offset, length int64) ([]byte, error) {
+	chunk, err := s.read(file, offset, length)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(chunk.Data), nil
+}
+
+// the last n lines of the file and the offset where the file ends, scanning
+// at most TAIL_MAX_BYTES backwards
+func (s *Sandbox) Tail(file string, n int) ([]byte, int64, error) {
+	size, err := s.Size(file)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data := make([]byte, 0)
+	offset := size
+	for offset > 0 && size-offset < TAIL_MAX_BYTES {
+		length := int64(READ_CHUNK_SIZE)
+		if offset < length {
+			length = offset
+		}
+		offset -= length
+
+		chunk, err := s.Read(file, offset, length)
+		if err != nil {
+			return nil, 0, err
+		}
+		data = append(chunk, data...)
+
+		// the trailing newline ends the last line rather than starting a new one
+		if bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) >= n {
+			break
+		}
+	}
+
+	return tailLines(data, n), size, nil
+}
+
+func tailLines(data []byte, n int) []byte {
+	end := len(bytes.TrimSuffix(data, []byte("\n")))
+	for i := end - 1; i >= 0; i-- {
+		if data[i] != '\n' {
+			continue
+		}
+
+		n -= 1
+		if n == 0 {
+			return data[i+1:]
+		}
+	}
+
+	return data
+}
+
+func (s *Sandbox) read(file string, offset, length int64) (*fileChunk, error) {
+	query := url.Values{}
+	query.Set("path", s.Directory+"/"+file)
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	if length > 0 {
+		query.Set("length", strconv.FormatInt(length, 10))
+	}
+
+	u := url.URL{
+		Scheme:   "http",
+		Host:     s.Agent,
+		Path:     "/files/read",
+		RawQuery: query.Encode(),
+	}
+
+	resp, err := s.client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("read %s from agent %s got status %d", file, s.Agent, resp.StatusCode)
+	}
+
+	var chunk fileChunk
+	if err := json.NewDecoder(resp.Body).Decode(&chunk); err != nil {
+		return nil, err
+	}
+
+	return &chunk, nil
+}
@@ -0,0 +1,67 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/megos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorDirectory(t *testing.T) {
+	state := &megos.State{
+		Frameworks: []megos.Framework{
+			{Executors: []megos.Executor{{ID: "0-web", Directory: "/sandbox/0-web"}}},
+		},
+		CompletedFrameworks: []megos.Framework{
+			{CompletedExecutors: []megos.Executor{
+				{ID: "executor", Directory: "/sandbox/executor", CompletedTasks: []megos.Task{{ID: "1-web"}}},
+			}},
+		},
+	}
+
+	assert.Equal(t, "/sandbox/0-web", executorDirectory(state, "0-web"))
+	assert.Equal(t, "/sandbox/executor", executorDirectory(state, "1-web"))
+	assert.Equal(t, "", executorDirectory(state, "2-web"))
+}
+
+func TestTail(t *testing.T) {
+	content := strings.Repeat("line\n", READ_CHUNK_SIZE/4) + "first\nsecond\nthird\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sandbox/stdout", r.URL.Query().Get("path"))
+
+		offset, _ := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+		if offset < 0 {
+			json.NewEncoder(w).Encode(&fileChunk{Offset: int64(len(content))})
+			return
+		}
+
+		end := int64(len(content))
+		if length, err := strconv.ParseInt(r.URL.Query().Get("length"), 10, 64); err == nil && offset+length < end {
+			end = offset + length
+		}
+		json.NewEncoder(w).Encode(&fileChunk{Data: content[offset:end], Offset: offset})
+	}))
+	defer server.Close()
+
+	box := &Sandbox{
+		Agent:     strings.TrimPrefix(server.URL, "http://"),
+		Directory: "/sandbox",
+		client:    http.DefaultClient,
+	}
+
+	data, offset, err := box.Tail("stdout", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "second\nthird\n", string(data))
+	assert.Equal(t, int64(len(content)), offset)
+
+	data, _, err = box.Tail("stdout", READ_CHUNK_SIZE/4+1)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "line\nline\n"))
+	assert.True(t, strings.HasSuffix(string(data), "line\nfirst\nsecond\nthird\n"))
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	for _, expr := range []string{"* * * * *", "*/5 0-6 1,15 * 1-5", "@daily", "0 12 * * 7"} {
+		_, err := parseCronSchedule(expr)
+		assert.Nil(t, err, expr)
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := parseCronSchedule(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2017, time.March, 10, 10, 30, 15, 0, time.UTC) // friday
+
+	cases := map[string]time.Time{
+		"* * * * *":    time.Date(2017, time.March, 10, 10, 31, 0, 0, time.UTC),
+		"*/15 * * * *": time.Date(2017, time.March, 10, 10, 45, 0, 0, time.UTC),
+		"0 9 * * *":    time.Date(2017, time.March, 11, 9, 0, 0, 0, time.UTC),
+		"0 0 * * 1":    time.Date(2017, time.March, 13, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * *":    time.Date(2017, time.April, 1, 0, 0, 0, 0, time.UTC),
+		"@yearly":      time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+		// either day field matching
+		"0 0 20 * 0": time.Date(2017, time.March, 12, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *": time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC),
+	}
+
+	for expr, expected := range cases {
+		s, err := parseCronSchedule(expr)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, s.Next(from), expr)
+	}
+
+	s, _ := parseCronSchedule("0 0 30 2 *")
+	assert.True(t, s.Next(from).IsZero())
+}
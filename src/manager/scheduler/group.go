@@ -0,0 +1,669 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/connector"
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	GROUP_STATE_CREATING = "creating"
+	GROUP_STATE_UPDATING = "updating"
+	GROUP_STATE_SCALING  = "scaling"
+	GROUP_STATE_DELETING = "deleting"
+	GROUP_STATE_NORMAL   = "normal"
+	GROUP_STATE_FAILED   = "failed"
+
+	GROUP_POLL_INTERVAL = 2 * time.Second
+)
+
+var errGroupOperationAborted = errors.New("group operation aborted")
+
+// at most one operation is running for a group, deleting aborts the running one
+type groupOperation struct {
+	abort chan struct{}
+	done  chan struct{}
+}
+
+type groupOperations struct {
+	ops map[string]*groupOperation
+	sync.Mutex
+}
+
+func (scheduler *Scheduler) CreateGroup(spec *types.GroupVersion) (*store.Group, error) {
+	if len(spec.Apps) == 0 {
+		return nil, errors.New("at least one app required in a group")
+	}
+
+	members, runAs, err := groupMembersFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, member := range spec.Apps {
+		if err := state.ValidateVersion(member.Version); err != nil {
+			return nil, fmt.Errorf("app %s: %s", member.Version.AppName, err.Error())
+		}
+
+		if scheduler.AppStorage.Get(members[i].AppID) != nil {
+			return nil, fmt.Errorf("app %s already exists", members[i].AppID)
+		}
+	}
+
+	now := time.Now().UnixNano()
+	group := &store.Group{
+		ID:        fmt.Sprintf("%s-%s-%s", spec.Name, runAs, connector.Instance().ClusterID),
+		Name:      spec.Name,
+		RunAs:     runAs,
+		ClusterID: connector.Instance().ClusterID,
+		State:     GROUP_STATE_CREATING,
+		Timeout:   spec.Timeout,
+		Members:   members,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := store.DB().CreateGroup(group); err != nil {
+		return nil, err
+	}
+
+	scheduler.startGroupOperation(group, scheduler.createGroupApps)
+
+	return group, nil
+}
+
+func (scheduler *Scheduler) InspectGroup(groupId string) (*store.Group, error) {
+	group := store.DB().GetGroup(groupId)
+	if group == nil {
+		return nil, errors.New("group not exists")
+	}
+
+	return group, nil
+}
+
+func (scheduler *Scheduler) ListGroups() []*store.Group {
+	return store.DB().ListGroups()
+}
+
+// all apps of the group are checked before any of them is touched,
+// apps can not be added to or removed from a group by update
+func (scheduler *Scheduler) UpdateGroup(groupId string, spec *types.GroupVersion) (*store.Group, error) {
+	group, err := scheduler.idleGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Name != group.Name {
+		return nil, errors.New("group name can not be changed")
+	}
+
+	members, runAs, err := groupMembersFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if runAs != group.RunAs {
+		return nil, errors.New("runAs of group can not be changed")
+	}
+
+	if len(members) != len(group.Members) {
+		return nil, errors.New("apps can not be added to or removed from group by update")
+	}
+
+	for i, member := range members {
+		if findGroupMember(group.Members, member.AppName) == nil {
+			return nil, errors.New("apps can not be added to or removed from group by update")
+		}
+
+		app := scheduler.AppStorage.Get(member.AppID)
+		if app == nil {
+			return nil, fmt.Errorf("app %s not exists", member.AppID)
+		}
+
+		version := spec.Apps[i].Version
+		autoProceed(version)
+
+		if preview := app.PreviewUpdate(version); !preview.Valid {
+			return nil, fmt.Errorf("app %s: %s", member.AppName, strings.Join(preview.Errors, "; "))
+		}
+
+		member.Version = state.VersionToRaft(version, member.AppID)
+		member.PreviousVersionID = app.CurrentVersion.ID
+	}
+
+	group.Members = members
+	group.Timeout = spec.Timeout
+	scheduler.setGroupState(group, GROUP_STATE_UPDATING, "")
+
+	scheduler.startGroupOperation(group, scheduler.updateGroupApps)
+
+	return group, nil
+}
+
+func (scheduler *Scheduler) ScaleGroup(groupId string, param *types.ScaleGroupParam) error {
+	group, err := scheduler.idleGroup(groupId)
+	if err != nil {
+		return err
+	}
+
+	if len(param.Instances) == 0 {
+		return errors.New("specify instances of apps want to scale")
+	}
+
+	for name, instances := range param.Instances {
+		member := findGroupMember(group.Members, name)
+		if member == nil {
+			return fmt.Errorf("app %s not in group %s", name, group.Name)
+		}
+
+		app := scheduler.AppStorage.Get(member.AppID)
+		if app == nil {
+			return fmt.Errorf("app %s not exists", member.AppID)
+		}
+
+		if !app.StateIs(state.APP_STATE_NORMAL) {
+			return fmt.Errorf("app %s is %s now, can not scale", member.AppID, app.StateMachine.ReadableState())
+		}
+
+		if instances < 0 {
+			return fmt.Errorf("instances of app %s should not be negative", name)
+		}
+
+		diff := instances - int(app.CurrentVersion.Instances)
+		if app.IsFixed() && diff > 0 && len(param.IPs[name]) != diff {
+			return fmt.Errorf("please provide %d unique ip for app %s", diff, name)
+		}
+	}
+
+	for _, member := range group.Members {
+		member.Version = nil
+		member.Instances = -1
+		member.IPs = nil
+		member.PreviousInstances = 0
+		member.PreviousIPs = nil
+
+		if instances, ok := param.Instances[member.AppName]; ok {
+			member.Instances = int32(instances)
+			member.IPs = param.IPs[member.AppName]
+
+			app := scheduler.AppStorage.Get(member.AppID)
+			member.PreviousInstances = app.CurrentVersion.Instances
+			member.PreviousIPs = append([]string{}, app.CurrentVersion.IP...)
+		}
+	}
+
+	scheduler.setGroupState(group, GROUP_STATE_SCALING, "")
+
+	scheduler.startGroupOperation(group, scheduler.scaleGroupApps)
+
+	return nil
+}
+
+// deleting aborts any operation running on the group,
+// apps are deleted in reverse order of their dependencies
+func (scheduler *Scheduler) DeleteGroup(groupId string) error {
+	group := store.DB().GetGroup(groupId)
+	if group == nil {
+		return errors.New("group not exists")
+	}
+
+	scheduler.abortGroupOperation(groupId)
+
+	scheduler.setGroupState(group, GROUP_STATE_DELETING, "")
+
+	scheduler.startGroupOperation(group, scheduler.deleteGroupApps)
+
+	return nil
+}
+
+// resume operations interrupted by manager failover
+func (scheduler *Scheduler) recoverGroups() {
+	for _, group := range store.DB().ListGroups() {
+		// left over from previous leadership
+		scheduler.abortGroupOperation(group.ID)
+
+		switch group.State {
+		case GROUP_STATE_CREATING:
+			scheduler.startGroupOperation(group, scheduler.createGroupApps)
+		case GROUP_STATE_SCALING:
+			scheduler.startGroupOperation(group, scheduler.scaleGroupApps)
+		case GROUP_STATE_DELETING:
+			scheduler.startGroupOperation(group, scheduler.deleteGroupApps)
+		case GROUP_STATE_UPDATING:
+			// apps may be left half updated, let user decide what to do
+			scheduler.setGroupState(group, GROUP_STATE_FAILED, "update interrupted by manager failover")
+		}
+	}
+}
+
+func (scheduler *Scheduler) idleGroup(groupId string) (*store.Group, error) {
+	group := store.DB().GetGroup(groupId)
+	if group == nil {
+		return nil, errors.New("group not exists")
+	}
+
+	if group.State != GROUP_STATE_NORMAL && group.State != GROUP_STATE_FAILED {
+		return nil, fmt.Errorf("group is %s now, try again later", group.State)
+	}
+
+	return group, nil
+}
+
+func (scheduler *Scheduler) setGroupState(group *store.Group, groupState, message string) {
+	group.State = groupState
+	group.Message = message
+	group.UpdatedAt = time.Now().UnixNano()
+
+	if err := store.DB().UpdateGroup(group); err != nil {
+		logrus.Errorf("update group %s failed: %s", group.ID, err.Error())
+	}
+}
+
+func (scheduler *Scheduler) startGroupOperation(group *store.Group, fn func(*store.Group, <-chan struct{}) error) {
+	op := &groupOperation{
+		abort: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	scheduler.groupOps.Lock()
+	scheduler.groupOps.ops[group.ID] = op
+	scheduler.groupOps.Unlock()
+
+	go func() {
+		defer close(op.done)
+
+		err := fn(group, op.abort)
+		if err == errGroupOperationAborted {
+			return
+		}
+
+		scheduler.groupOps.Lock()
+		delete(scheduler.groupOps.ops, group.ID)
+		scheduler.groupOps.Unlock()
+
+		if err != nil {
+			logrus.Errorf("group %s %s failed: %s", group.ID, group.State, err.Error())
+			scheduler.setGroupState(group, GROUP_STATE_FAILED, err.Error())
+			return
+		}
+
+		if group.State != GROUP_STATE_DELETING {
+			scheduler.setGroupState(group, GROUP_STATE_NORMAL, "")
+		}
+	}()
+}
+
+func (scheduler *Scheduler) abortGroupOperation(groupId string) {
+	scheduler.groupOps.Lock()
+	op, found := scheduler.groupOps.ops[groupId]
+	delete(scheduler.groupOps.ops, groupId)
+	scheduler.groupOps.Unlock()
+
+	if found {
+		close(op.abort)
+		<-op.done
+	}
+}
+
+// apps are created one by one, each after all of its dependencies are ready,
+// apps already created are deleted if any of them failed to be created
+func (scheduler *Scheduler) createGroupApps(group *store.Group, abort <-chan struct{}) error {
+	ordered, err := sortGroupMembers(group.Members)
+	if err != nil {
+		return err
+	}
+
+	created := make([]*store.GroupMember, 0)
+	for _, member := range ordered {
+		if scheduler.AppStorage.Get(member.AppID) == nil {
+			if _, err := scheduler.CreateApp(state.VersionFromRaft(member.Version)); err != nil {
+				scheduler.removeGroupApps(created)
+				return fmt.Errorf("create app %s failed: %s, created apps removed", member.AppName, err.Error())
+			}
+		}
+		created = append(created, member)
+
+		if err := scheduler.waitGroupApp(group, member.AppID, abort); err != nil {
+			if err != errGroupOperationAborted {
+				scheduler.removeGroupApps(created)
+				err = fmt.Errorf("%s, created apps removed", err.Error())
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apps already updated are rolled back if any of them failed
+func (scheduler *Scheduler) updateGroupApps(group *store.Group, abort <-chan struct{}) error {
+	ordered, err := sortGroupMembers(group.Members)
+	if err != nil {
+		return err
+	}
+
+	updated := make([]*store.GroupMember, 0)
+	for _, member := range ordered {
+		err := scheduler.UpdateApp(member.AppID, state.VersionFromRaft(member.Version))
+		if err == nil {
+			updated = append(updated, member)
+			err = scheduler.waitGroupApp(group, member.AppID, abort)
+		}
+
+		if err != nil {
+			if err != errGroupOperationAborted {
+				scheduler.rollbackGroupApps(updated)
+				err = fmt.Errorf("update app %s failed: %s, updated apps rolled back", member.AppName, err.Error())
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apps already scaled are restored to their previous instances if any of
+// them failed
+func (scheduler *Scheduler) scaleGroupApps(group *store.Group, abort <-chan struct{}) error {
+	ordered, err := sortGroupMembers(group.Members)
+	if err != nil {
+		return err
+	}
+
+	scaled := make([]*store.GroupMember, 0)
+	for _, member := range ordered {
+		app := scheduler.AppStorage.Get(member.AppID)
+		if app == nil {
+			scheduler.restoreGroupInstances(scaled)
+			return fmt.Errorf("app %s not exists, scaled apps restored", member.AppID)
+		}
+
+		// members not mentioned keep their instances, apps already scaled
+		// are just waited when resumed after failover
+		if member.Instances >= 0 {
+			diff := int(member.Instances) - int(app.CurrentVersion.Instances)
+			if diff > 0 {
+				err = scheduler.ScaleUp(member.AppID, diff, member.IPs)
+			} else if diff < 0 {
+				err = app.ScaleDown(-diff)
+			}
+
+			if err != nil {
+				scheduler.restoreGroupInstances(scaled)
+				return fmt.Errorf("scale app %s failed: %s, scaled apps restored", member.AppName, err.Error())
+			}
+
+			scaled = append(scaled, member)
+		}
+
+		if err := scheduler.waitGroupApp(group, member.AppID, abort); err != nil {
+			if err != errGroupOperationAborted {
+				scheduler.restoreGroupInstances(scaled)
+				err = fmt.Errorf("%s, scaled apps restored", err.Error())
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (scheduler *Scheduler) deleteGroupApps(group *store.Group, abort <-chan struct{}) error {
+	ordered, err := sortGroupMembers(group.Members)
+	if err != nil {
+		return err
+	}
+
+	for i := len(ordered) - 1; i >= 0; i-- {
+		member := ordered[i]
+
+		app := scheduler.AppStorage.Get(member.AppID)
+		if app != nil && !app.StateIs(state.APP_STATE_DELETING) {
+			if err := app.Delete(); err != nil {
+				return fmt.Errorf("delete app %s failed: %s", member.AppName, err.Error())
+			}
+		}
+
+		if err := scheduler.waitGroupAppRemoved(member.AppID, abort); err != nil {
+			return err
+		}
+	}
+
+	return store.DB().DeleteGroup(group.ID)
+}
+
+func (scheduler *Scheduler) removeGroupApps(members []*store.GroupMember) {
+	for i := len(members) - 1; i >= 0; i-- {
+		if err := scheduler.DeleteApp(members[i].AppID); err != nil {
+			logrus.Errorf("remove app %s of group failed: %s", members[i].AppID, err.Error())
+		}
+	}
+}
+
+func (scheduler *Scheduler) rollbackGroupApps(members []*store.GroupMember) {
+	for i := len(members) - 1; i >= 0; i-- {
+		app := scheduler.AppStorage.Get(members[i].AppID)
+		if app == nil {
+			continue
+		}
+
+		var err error
+		if app.ProposedVersion != nil {
+			err = app.CancelUpdate()
+		} else if app.CurrentVersion.ID != members[i].PreviousVersionID {
+			err = app.Rollback(members[i].PreviousVersionID)
+		}
+
+		if err != nil {
+			logrus.Errorf("rollback app %s of group failed: %s", members[i].AppID, err.Error())
+		}
+	}
+}
+
+// instances are counted by slots as apps may be left in the middle of scaling,
+// restoring doesn't go through quota as it only gives back what was used
+func (scheduler *Scheduler) restoreGroupInstances(members []*store.GroupMember) {
+	for i := len(members) - 1; i >= 0; i-- {
+		member := members[i]
+		app := scheduler.AppStorage.Get(member.AppID)
+		if app == nil {
+			continue
+		}
+
+		var err error
+		current := len(app.GetSlots())
+		if diff := int(member.PreviousInstances) - current; diff > 0 {
+			var ips []string
+			if app.IsFixed() && len(member.PreviousIPs) >= int(member.PreviousInstances) {
+				ips = member.PreviousIPs[current:member.PreviousInstances]
+			}
+			err = app.ScaleUp(diff, ips)
+		} else if diff < 0 {
+			err = app.ScaleDown(-diff)
+		}
+
+		if err != nil {
+			logrus.Errorf("restore instances of app %s of group failed: %s", member.AppID, err.Error())
+		}
+	}
+}
+
+// wait until all slots of the app are running and healthy
+func (scheduler *Scheduler) waitGroupApp(group *store.Group, appId string, abort <-chan struct{}) error {
+	var timeout <-chan time.Time
+	if group.Timeout > 0 {
+		timeout = time.After(time.Duration(group.Timeout) * time.Second)
+	}
+
+	ticker := time.NewTicker(GROUP_POLL_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		app := scheduler.AppStorage.Get(appId)
+		if app == nil {
+			return fmt.Errorf("app %s not exists", appId)
+		}
+
+		if groupAppReady(app) {
+			return nil
+		}
+
+		select {
+		case <-abort:
+			return errGroupOperationAborted
+		case <-timeout:
+			return fmt.Errorf("app %s not ready in %d seconds", appId, group.Timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (scheduler *Scheduler) waitGroupAppRemoved(appId string, abort <-chan struct{}) error {
+	ticker := time.NewTicker(GROUP_POLL_INTERVAL)
+	defer ticker.Stop()
+
+	for scheduler.AppStorage.Get(appId) != nil {
+		select {
+		case <-abort:
+			return errGroupOperationAborted
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+func groupAppReady(app *state.App) bool {
+	if !app.StateIs(state.APP_STATE_NORMAL) || app.ProposedVersion != nil {
+		return false
+	}
+
+	slots := app.GetSlots()
+	if len(slots) != int(app.CurrentVersion.Instances) {
+		return false
+	}
+
+	for _, slot := range slots {
+		if !slot.StateIs(state.SLOT_STATE_TASK_RUNNING) || !slot.Healthy() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func groupMembersFromSpec(spec *types.GroupVersion) ([]*store.GroupMember, string, error) {
+	if spec.Name == "" {
+		return nil, "", errors.New("group name required")
+	}
+
+	runAs := ""
+	members := make([]*store.GroupMember, 0)
+	for _, member := range spec.Apps {
+		if member.Version == nil {
+			return nil, "", errors.New("version of group app required")
+		}
+
+		if runAs == "" {
+			runAs = member.Version.RunAs
+		} else if member.Version.RunAs != runAs {
+			return nil, "", errors.New("apps of a group should run as the same user")
+		}
+
+		appName := strings.TrimSpace(member.Version.AppName)
+		if findGroupMember(members, appName) != nil {
+			return nil, "", fmt.Errorf("duplicated app %s in group", appName)
+		}
+
+		appID := fmt.Sprintf("%s-%s-%s", appName, member.Version.RunAs, connector.Instance().ClusterID)
+		members = append(members, &store.GroupMember{
+			AppID:     appID,
+			AppName:   appName,
+			DependsOn: member.DependsOn,
+			Version:   state.VersionToRaft(member.Version, appID),
+		})
+	}
+
+	if _, err := sortGroupMembers(members); err != nil {
+		return nil, "", err
+	}
+
+	return members, runAs, nil
+}
+
+// order members so that every app comes after its dependencies,
+// apps without dependencies between them keep the order they are given
+func sortGroupMembers(members []*store.GroupMember) ([]*store.GroupMember, error) {
+	for _, member := range members {
+		for _, dep := range member.DependsOn {
+			if dep == member.AppName {
+				return nil, fmt.Errorf("app %s depends on itself", dep)
+			}
+
+			if findGroupMember(members, dep) == nil {
+				return nil, fmt.Errorf("app %s depends on %s which is not in group", member.AppName, dep)
+			}
+		}
+	}
+
+	sorted := make([]*store.GroupMember, 0)
+	visited := make(map[string]bool)
+	for len(sorted) < len(members) {
+		progressed := false
+		for _, member := range members {
+			if visited[member.AppName] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range member.DependsOn {
+				if !visited[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				visited[member.AppName] = true
+				sorted = append(sorted, member)
+				progressed = true
+			}
+		}
+
+		if !progressed {
+			return nil, errors.New("circular dependencies found between apps of group")
+		}
+	}
+
+	return sorted, nil
+}
+
+func findGroupMember(members []*store.GroupMember, appName string) *store.GroupMember {
+	for _, member := range members {
+		if member.AppName == appName {
+			return member
+		}
+	}
+
+	return nil
+}
+
+// rolling updates of group apps proceed without user intervention
+func autoProceed(version *types.Version) {
+	if version.UpdatePolicy == nil {
+		version.UpdatePolicy = &types.UpdatePolicy{}
+	}
+
+	if version.UpdatePolicy.BatchSize <= 0 {
+		version.UpdatePolicy.BatchSize = 1
+	}
+}
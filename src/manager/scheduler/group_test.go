@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+	"github.com/Dataman-Cloud/swan/src/manager/connector"
+	"github.com/Dataman-Cloud/swan/src/manager/event"
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestSortGroupMembers(t *testing.T) {
+	members := []*store.GroupMember{
+		{AppName: "web", DependsOn: []string{"cache", "db"}},
+		{AppName: "cache", DependsOn: []string{"db"}},
+		{AppName: "db"},
+		{AppName: "log"},
+	}
+
+	sorted, err := sortGroupMembers(members)
+	assert.Nil(t, err)
+
+	names := make([]string, 0)
+	for _, member := range sorted {
+		names = append(names, member.AppName)
+	}
+	assert.Equal(t, []string{"db", "log", "cache", "web"}, names)
+}
+
+func TestSortGroupMembersInvalid(t *testing.T) {
+	_, err := sortGroupMembers([]*store.GroupMember{
+		{AppName: "a", DependsOn: []string{"b"}},
+		{AppName: "b", DependsOn: []string{"a"}},
+	})
+	assert.NotNil(t, err)
+
+	_, err = sortGroupMembers([]*store.GroupMember{
+		{AppName: "a", DependsOn: []string{"c"}},
+	})
+	assert.NotNil(t, err)
+
+	_, err = sortGroupMembers([]*store.GroupMember{
+		{AppName: "a", DependsOn: []string{"a"}},
+	})
+	assert.NotNil(t, err)
+}
+
+// app created with store kept in memory and calls accepted by a fake mesos
+// master, all slots running
+func newGroupTestApp(t *testing.T, name string, instances int32) *state.App {
+	version := &types.Version{
+		AppName:   name,
+		RunAs:     "test",
+		Instances: instances,
+		CPUs:      0.1,
+		Mem:       16,
+		Container: &types.Container{
+			Docker: &types.Docker{Image: "nginx", Network: "bridge"},
+		},
+	}
+
+	app, err := state.NewApp(version, make(chan *event.UserEvent, 16))
+	assert.Nil(t, err)
+
+	for i := 0; i < int(instances); i++ {
+		slot, _ := app.GetSlot(i)
+		slot.SetState(state.SLOT_STATE_TASK_RUNNING)
+		slot.SetHealthy(true)
+	}
+	assert.True(t, app.StateIs(state.APP_STATE_NORMAL))
+
+	return app
+}
+
+func TestScaleGroupRestoredOnFailure(t *testing.T) {
+	eventbus.Init()
+	go eventbus.Start(context.Background())
+
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer master.Close()
+
+	store.InitMemoryStore()
+	connector.Init("root", &url.URL{})
+	connector.Instance().MesosLeaderHttpClient = connector.NewHTTPClient(strings.TrimPrefix(master.URL, "http://"), "/api/v1/scheduler")
+
+	scheduler := &Scheduler{
+		AppStorage: NewMemoryStore(),
+		JobRuns:    NewMemoryStore(),
+		groupOps:   &groupOperations{ops: make(map[string]*groupOperation)},
+	}
+
+	group := &store.Group{ID: "g", Name: "g", State: GROUP_STATE_NORMAL}
+	for i, name := range []string{"first", "middle", "last"} {
+		app := newGroupTestApp(t, name, 2)
+		scheduler.AppStorage.Add(app.ID, app)
+
+		member := &store.GroupMember{AppID: app.ID, AppName: name}
+		if i > 0 {
+			member.DependsOn = []string{group.Members[i-1].AppName}
+		}
+		group.Members = append(group.Members, member)
+	}
+	assert.Nil(t, store.DB().CreateGroup(group))
+
+	// first scaled down to 1, middle can't go up to 4 within 6 instances
+	assert.Nil(t, store.DB().CreateQuota(&store.Quota{RunAs: "test", Instances: 6}))
+	param := &types.ScaleGroupParam{Instances: map[string]int{"first": 1, "middle": 4, "last": 1}}
+	assert.Nil(t, scheduler.ScaleGroup("g", param))
+
+	first := scheduler.AppStorage.Get(group.Members[0].AppID)
+	killed, _ := first.GetSlot(1)
+	assert.True(t, waitFor(func() bool { return killed.StateIs(state.SLOT_STATE_PENDING_KILL) }))
+	killed.SetState(state.SLOT_STATE_TASK_KILLED)
+
+	assert.True(t, waitFor(func() bool { return store.DB().GetGroup("g").State == GROUP_STATE_FAILED }))
+	assert.Contains(t, store.DB().GetGroup("g").Message, "scale app middle failed")
+
+	// first scaled up again, last left alone
+	assert.Equal(t, int32(2), first.CurrentVersion.Instances)
+	assert.Len(t, first.GetSlots(), 2)
+	last := scheduler.AppStorage.Get(group.Members[2].AppID)
+	assert.Equal(t, int32(2), last.CurrentVersion.Instances)
+}
+
+func waitFor(cond func() bool) bool {
+	for i := 0; i < 500; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
@@ -36,12 +36,15 @@ func NewHandlerManager(SchedulerRef *Scheduler) *HandlerManager {
 
 	m.Register(event.EVENT_TYPE_MESOS_SUBSCRIBED, LoggerHandler, SubscribedHandler)
 	m.Register(event.EVENT_TYPE_MESOS_HEARTBEAT, LoggerHandler, DummyHandler)
-	m.Register(event.EVENT_TYPE_MESOS_OFFERS, LoggerHandler, OfferHandler, DummyHandler)
-	m.Register(event.EVENT_TYPE_MESOS_RESCIND, LoggerHandler, DummyHandler)
+	// agents going into maintenance are known before their offers are used
+	m.Register(event.EVENT_TYPE_MESOS_OFFERS, LoggerHandler, InverseOfferHandler, OfferHandler, DummyHandler)
+	m.Register(event.EVENT_TYPE_MESOS_RESCIND, LoggerHandler, RecindHandler)
 	m.Register(event.EVENT_TYPE_MESOS_UPDATE, LoggerHandler, UpdateHandler, DummyHandler)
 	m.Register(event.EVENT_TYPE_MESOS_FAILURE, LoggerHandler, DummyHandler)
 	m.Register(event.EVENT_TYPE_MESOS_MESSAGE, LoggerHandler, DummyHandler)
 	m.Register(event.EVENT_TYPE_MESOS_ERROR, LoggerHandler, DummyHandler)
+	m.Register(event.EVENT_TYPE_MESOS_INVERSE_OFFERS, LoggerHandler, InverseOfferHandler)
+	m.Register(event.EVENT_TYPE_MESOS_RESCIND_INVERSE_OFFER, LoggerHandler, RescindInverseOfferHandler)
 	m.Register(event.EVENT_TYPE_USER_INVALID_APPS, LoggerHandler, InvalidAppHandler)
 
 	return m
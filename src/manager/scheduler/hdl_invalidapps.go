@@ -13,6 +13,7 @@ func InvalidAppHandler(s *Scheduler, ev event.Event) error {
 	appID, ok := ev.GetEvent().(string)
 	if ok {
 		s.AppStorage.Delete(appID)
+		s.JobRuns.Delete(appID)
 	}
 	return nil
 }
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"github.com/Dataman-Cloud/swan/src/manager/event"
+	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
+	"github.com/Dataman-Cloud/swan/src/mesosproto/sched"
+)
+
+// inverse offers come along with offers, or by themselves since mesos 1.0
+func InverseOfferHandler(s *Scheduler, ev event.Event) error {
+	e, ok := ev.GetEvent().(*sched.Event)
+	if !ok {
+		return errUnexpectedEventType
+	}
+
+	var inverseOffers []*mesos.InverseOffer
+	switch e.GetType() {
+	case sched.Event_OFFERS:
+		inverseOffers = e.GetOffers().GetInverseOffers()
+	case sched.Event_INVERSE_OFFERS:
+		inverseOffers = e.GetInverseOffers().GetInverseOffers()
+	}
+
+	for _, inverseOffer := range inverseOffers {
+		s.scheduleMaintenance(inverseOffer)
+	}
+
+	return nil
+}
+
+func RescindInverseOfferHandler(s *Scheduler, ev event.Event) error {
+	e, ok := ev.GetEvent().(*sched.Event)
+	if !ok {
+		return errUnexpectedEventType
+	}
+
+	s.cancelMaintenance(e.GetRescindInverseOffer().GetInverseOfferId().GetValue())
+
+	return nil
+}
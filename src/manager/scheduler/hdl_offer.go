@@ -7,7 +7,7 @@ import (
 	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
 	"github.com/Dataman-Cloud/swan/src/mesosproto/sched"
 
-	"github.com/golang/protobuf/proto"
+	"github.com/Sirupsen/logrus"
 )
 
 func OfferHandler(s *Scheduler, ev event.Event) error {
@@ -17,9 +17,19 @@ func OfferHandler(s *Scheduler, ev event.Event) error {
 	}
 
 	for _, offer := range e.Offers.Offers {
+		// slots are moving off agents going into maintenance
+		if s.underMaintenance(offer.GetAgentId().GetValue()) {
+			RejectOffer(offer)
+			continue
+		}
+
+		offerID := offer.GetId().GetValue()
+		state.OfferAllocatorInstance().BeginOffer(offerID)
+
 		// when no pending offer slot
 		offerWrapper := state.NewOfferWrapper(offer)
 		taskInfos := make([]*mesos.TaskInfo, 0)
+		reservedSlots := make([]*state.Slot, 0)
 		nonMatchedSlots := make([]*state.Slot, 0)
 		for {
 			// loop through all pending offer slots
@@ -35,8 +45,9 @@ func OfferHandler(s *Scheduler, ev event.Event) error {
 				_, taskInfo := slot.ReserveOfferAndPrepareTaskInfo(offerWrapper)
 				state.OfferAllocatorInstance().SetOfferSlotMap(offer, slot)
 				taskInfos = append(taskInfos, taskInfo)
+				reservedSlots = append(reservedSlots, slot)
 			} else {
-				// put the slot back into the queue, in the end
+				// put the slot back into the queue after this offer is used up
 				nonMatchedSlots = append(nonMatchedSlots, slot)
 			}
 		}
@@ -45,6 +56,18 @@ func OfferHandler(s *Scheduler, ev event.Event) error {
 			state.OfferAllocatorInstance().PutSlotBackToPendingQueue(slot)
 		}
 
+		// resources of the offer are gone, slots wait for other offers
+		if state.OfferAllocatorInstance().FinishOffer(offerID) {
+			logrus.Warnf("offer %s rescinded while being handled, %d reservations undone", offerID, len(reservedSlots))
+			for _, slot := range reservedSlots {
+				state.OfferAllocatorInstance().RemoveOfferSlotMapBySlot(slot)
+				if slot.StateIs(state.SLOT_STATE_PENDING_OFFER) {
+					state.OfferAllocatorInstance().PutSlotBackToPendingQueue(slot)
+				}
+			}
+			continue
+		}
+
 		if len(taskInfos) > 0 {
 			LaunchTaskInfos(offer, taskInfos)
 		} else { // reject offer here
@@ -52,9 +75,20 @@ func OfferHandler(s *Scheduler, ev event.Event) error {
 		}
 	}
 
+	if len(state.OfferAllocatorInstance().PendingSlots()) == 0 {
+		connector.Instance().SuppressOffers()
+	}
+
 	return nil
 }
 
+// slots might be queued right after offers got suppressed
+func (scheduler *Scheduler) reviveIfPending() {
+	if connector.Instance().OffersSuppressed() && len(state.OfferAllocatorInstance().PendingSlots()) > 0 {
+		connector.Instance().ReviveOffers()
+	}
+}
+
 func LaunchTaskInfos(offer *mesos.Offer, taskInfos []*mesos.TaskInfo) {
 	call := &sched.Call{
 		FrameworkId: connector.Instance().FrameworkInfo.GetId(),
@@ -71,7 +105,7 @@ func LaunchTaskInfos(offer *mesos.Offer, taskInfos []*mesos.TaskInfo) {
 					},
 				},
 			},
-			Filters: &mesos.Filters{RefuseSeconds: proto.Float64(1)},
+			Filters: connector.Instance().LaunchFilters(offer.GetAgentId().GetValue()),
 		},
 	}
 
@@ -88,9 +122,7 @@ func RejectOffer(offer *mesos.Offer) {
 					Value: offer.GetId().Value,
 				},
 			},
-			Filters: &mesos.Filters{
-				RefuseSeconds: proto.Float64(1),
-			},
+			Filters: connector.Instance().DeclineFilters(offer.GetAgentId().GetValue()),
 		},
 	}
 
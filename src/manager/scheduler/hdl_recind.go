@@ -2,18 +2,23 @@ package scheduler
 
 import (
 	"github.com/Dataman-Cloud/swan/src/manager/event"
+	"github.com/Dataman-Cloud/swan/src/manager/state"
 	"github.com/Dataman-Cloud/swan/src/mesosproto/sched"
 
 	"github.com/Sirupsen/logrus"
 )
 
+// reservations on the offer being handled are undone by offer handler
 func RecindHandler(s *Scheduler, ev event.Event) error {
-	logrus.WithFields(logrus.Fields{"handler": "recind"}).
-		Debugf("logger handler report got event type: %s", ev.GetEventType())
-
-	if _, ok := ev.GetEvent().(*sched.Event); !ok {
+	e, ok := ev.GetEvent().(*sched.Event)
+	if !ok {
 		return errUnexpectedEventType
 	}
 
+	offerID := e.GetRescind().GetOfferId().GetValue()
+	logrus.WithFields(logrus.Fields{"handler": "recind"}).Infof("offer %s rescinded", offerID)
+
+	state.OfferAllocatorInstance().RescindOffer(offerID)
+
 	return nil
 }
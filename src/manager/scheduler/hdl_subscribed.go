@@ -19,6 +19,7 @@ func SubscribedHandler(s *Scheduler, ev event.Event) error {
 
 	sub := e.GetSubscribed()
 	connector.Instance().SetFrameworkInfoId(*sub.FrameworkId.Value)
+	connector.Instance().ResetOfferFilters()
 
 	return store.DB().UpdateFrameworkId(*sub.FrameworkId.Value)
 }
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Dataman-Cloud/swan/src/manager/connector"
 	"github.com/Dataman-Cloud/swan/src/manager/event"
@@ -46,17 +47,29 @@ func UpdateHandler(s *Scheduler, ev event.Event) error {
 	logrus.Debugf("preparing set app %s slot %d to state %s", appId, slotIndex, taskState)
 
 	app := s.AppStorage.Get(appId)
+	if app == nil {
+		app = s.JobRuns.Get(appId)
+	}
 	if app == nil {
 		return fmt.Errorf("app not found: %s", appId)
 	}
 	logrus.Debugf("found app %s", app.ID)
 
-	slot, found := app.GetSlot(int(slotIndex))
+	// slots may be moved to other indices by blue/green update
+	slot, found := app.GetSlotByTaskID(slotName)
+	if !found {
+		slot, found = app.GetSlot(int(slotIndex))
+	}
 	if !found {
 		return fmt.Errorf("slot not found: %d", slotIndex)
 	}
 	logrus.Debugf("found slot %s", slot.ID)
 
+	// health check result reported by executor
+	if taskStatus.Healthy != nil {
+		slot.RecordHealthCheck(healthy, statusTime(taskStatus), message)
+	}
+
 	slot.SetHealthy(healthy)
 
 	switch taskState {
@@ -65,7 +78,11 @@ func UpdateHandler(s *Scheduler, ev event.Event) error {
 	case mesos.TaskState_TASK_STARTING:
 		slot.SetState(state.SLOT_STATE_TASK_STARTING)
 	case mesos.TaskState_TASK_RUNNING:
-		if !slot.StateIs(state.SLOT_STATE_TASK_RUNNING) { // set state to running only if is not previously marked as running
+		// still running while being killed, eg. health check reported while
+		// draining, or kill call lost by manager failover
+		if slot.StateIs(state.SLOT_STATE_PENDING_KILL) {
+			slot.EnsureKilled()
+		} else if !slot.StateIs(state.SLOT_STATE_TASK_RUNNING) { // set state to running only if is not previously marked as running
 			slot.CurrentTask.ContainerId = parseValue(`"Id": "(?P<value>\w+)`, string(data))
 			slot.CurrentTask.ContainerName = parseValue(`"Name": "(?P<value>/mesos-[\w\.-]+)`, string(data))
 
@@ -120,6 +137,15 @@ func AckUpdateEvent(taskStatus *mesos.TaskStatus) {
 	}
 }
 
+// time when the status was generated, timestamp is in seconds
+func statusTime(taskStatus *mesos.TaskStatus) time.Time {
+	if ts := taskStatus.GetTimestamp(); ts > 0 {
+		return time.Unix(0, int64(ts*float64(time.Second)))
+	}
+
+	return time.Now()
+}
+
 func parseValue(regEx, data string) string {
 	var compRegEx = regexp.MustCompile(regEx)
 	match := compRegEx.FindStringSubmatch(data)
@@ -0,0 +1,646 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/connector"
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	JOB_RUN_STATE_RUNNING   = "running"
+	JOB_RUN_STATE_SUCCEEDED = "succeeded"
+	JOB_RUN_STATE_FAILED    = "failed"
+	JOB_RUN_STATE_KILLED    = "killed"
+
+	JOB_RUN_TRIGGER_SCHEDULE = "schedule"
+	JOB_RUN_TRIGGER_MANUAL   = "manual"
+
+	JOB_POLL_INTERVAL = 2 * time.Second
+	// runs kept in store for each job, the oldest are pruned
+	JOB_RUN_HISTORY_LIMIT = 50
+)
+
+// a run of a job is carried out by an app with the same id as the job, kept
+// in scheduler.JobRuns instead of AppStorage, so at most one run of a job is
+// in progress at any time
+type jobOperations struct {
+	timers map[string]*time.Timer
+	sync.Mutex
+}
+
+func (scheduler *Scheduler) CreateJob(spec *types.JobSpec) (*store.Job, error) {
+	version, err := jobVersionFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	jobID := fmt.Sprintf("%s-%s-%s", spec.Name, spec.RunAs, connector.Instance().ClusterID)
+	if scheduler.AppStorage.Get(jobID) != nil {
+		return nil, errors.New("app with the same name already exists")
+	}
+
+	now := time.Now().UnixNano()
+	job := &store.Job{
+		ID:          jobID,
+		Name:        spec.Name,
+		RunAs:       spec.RunAs,
+		ClusterID:   connector.Instance().ClusterID,
+		Schedule:    spec.Schedule,
+		Parallelism: version.Instances,
+		Retries:     spec.Retries,
+		Version:     state.VersionToRaft(version, jobID),
+		Runs:        make([]*store.JobRun, 0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := store.DB().CreateJob(job); err != nil {
+		return nil, err
+	}
+
+	scheduler.scheduleJob(job)
+
+	return job, nil
+}
+
+func (scheduler *Scheduler) InspectJob(jobId string) (*store.Job, error) {
+	job := store.DB().GetJob(jobId)
+	if job == nil {
+		return nil, errors.New("job not exists")
+	}
+
+	return job, nil
+}
+
+func (scheduler *Scheduler) ListJobs() []*store.Job {
+	return store.DB().ListJobs()
+}
+
+// run in progress is left untouched, the new spec applies to later runs
+func (scheduler *Scheduler) UpdateJob(jobId string, spec *types.JobSpec) (*store.Job, error) {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	job := store.DB().GetJob(jobId)
+	if job == nil {
+		return nil, errors.New("job not exists")
+	}
+
+	if spec.Name != job.Name || spec.RunAs != job.RunAs {
+		return nil, errors.New("name and runAs of job can not be changed")
+	}
+
+	version, err := jobVersionFromSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Schedule = spec.Schedule
+	job.Parallelism = version.Instances
+	job.Retries = spec.Retries
+	job.Version = state.VersionToRaft(version, job.ID)
+	job.UpdatedAt = time.Now().UnixNano()
+
+	if err := store.DB().UpdateJob(job); err != nil {
+		return nil, err
+	}
+
+	scheduler.scheduleJobLocked(job)
+
+	return job, nil
+}
+
+// the run in progress, if any, is killed
+func (scheduler *Scheduler) DeleteJob(jobId string) error {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	job := store.DB().GetJob(jobId)
+	if job == nil {
+		return errors.New("job not exists")
+	}
+
+	scheduler.unscheduleJobLocked(jobId)
+
+	if app := scheduler.JobRuns.Get(jobId); app != nil {
+		killJobApp(app)
+	}
+
+	return store.DB().DeleteJob(jobId)
+}
+
+// start a run now regardless of the schedule
+func (scheduler *Scheduler) StartJobRun(jobId string) (*store.JobRun, error) {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	job := store.DB().GetJob(jobId)
+	if job == nil {
+		return nil, errors.New("job not exists")
+	}
+
+	return scheduler.startJobRunLocked(job, JOB_RUN_TRIGGER_MANUAL)
+}
+
+// runs of the job, latest first
+func (scheduler *Scheduler) ListJobRuns(jobId string) ([]*store.JobRun, error) {
+	job, err := scheduler.InspectJob(jobId)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*store.JobRun, 0)
+	for i := len(job.Runs) - 1; i >= 0; i-- {
+		runs = append(runs, scheduler.liveJobRun(job, job.Runs[i]))
+	}
+
+	return runs, nil
+}
+
+func (scheduler *Scheduler) InspectJobRun(jobId, runId string) (*store.JobRun, error) {
+	job, err := scheduler.InspectJob(jobId)
+	if err != nil {
+		return nil, err
+	}
+
+	run := findJobRun(job, runId)
+	if run == nil {
+		return nil, errors.New("job run not exists")
+	}
+
+	return scheduler.liveJobRun(job, run), nil
+}
+
+func (scheduler *Scheduler) KillJobRun(jobId, runId string) error {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	job := store.DB().GetJob(jobId)
+	if job == nil {
+		return errors.New("job not exists")
+	}
+
+	run := findJobRun(job, runId)
+	if run == nil {
+		return errors.New("job run not exists")
+	}
+
+	if run.State != JOB_RUN_STATE_RUNNING {
+		return fmt.Errorf("job run is %s already", run.State)
+	}
+
+	if app := scheduler.JobRuns.Get(jobId); app != nil {
+		countJobRunAttempts(run, app)
+		run.Tasks = jobRunTasks(app, run)
+		killJobApp(app)
+	}
+
+	scheduler.finishJobRunLocked(job, run, JOB_RUN_STATE_KILLED, "killed by user")
+
+	return nil
+}
+
+// tasks of the run in progress are taken from its app
+func (scheduler *Scheduler) liveJobRun(job *store.Job, run *store.JobRun) *store.JobRun {
+	if run.State != JOB_RUN_STATE_RUNNING {
+		return run
+	}
+
+	app := scheduler.JobRuns.Get(job.ID)
+	if app == nil {
+		return run
+	}
+
+	live := *run
+	live.Tasks = jobRunTasks(app, run)
+
+	return &live
+}
+
+// the active run, if any
+func (scheduler *Scheduler) ActiveJobRun(job *store.Job) *store.JobRun {
+	for _, run := range job.Runs {
+		if run.State == JOB_RUN_STATE_RUNNING {
+			return run
+		}
+	}
+
+	return nil
+}
+
+// the next time the job is triggered by its schedule
+func (scheduler *Scheduler) NextJobRun(job *store.Job) *time.Time {
+	if job.Schedule == "" {
+		return nil
+	}
+
+	s, err := parseCronSchedule(job.Schedule)
+	if err != nil {
+		return nil
+	}
+
+	next := s.Next(time.Now())
+	if next.IsZero() {
+		return nil
+	}
+
+	return &next
+}
+
+// resume runs interrupted by manager failover, apps of the runs are already
+// recovered into scheduler.JobRuns
+func (scheduler *Scheduler) recoverJobs() {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	for _, job := range store.DB().ListJobs() {
+		if run := scheduler.ActiveJobRun(job); run != nil {
+			if app := scheduler.JobRuns.Get(job.ID); app != nil {
+				// failed tasks are relaunched by restart policies which are not persisted
+				for _, slot := range app.GetSlots() {
+					slot.StartRestartPolicy()
+				}
+
+				go scheduler.waitJobRun(job.ID, run.ID)
+			} else {
+				scheduler.finishJobRunLocked(job, run, JOB_RUN_STATE_FAILED, "run lost by manager failover")
+			}
+		}
+
+		scheduler.scheduleJobLocked(job)
+	}
+
+	// apps left over by runs which were over or jobs which were deleted
+	for id, app := range scheduler.JobRuns.Data() {
+		if job := store.DB().GetJob(id); job == nil || scheduler.ActiveJobRun(job) == nil {
+			killJobApp(app)
+		}
+	}
+}
+
+func (scheduler *Scheduler) scheduleJob(job *store.Job) {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	scheduler.scheduleJobLocked(job)
+}
+
+func (scheduler *Scheduler) scheduleJobLocked(job *store.Job) {
+	scheduler.unscheduleJobLocked(job.ID)
+
+	next := scheduler.NextJobRun(job)
+	if next == nil {
+		return
+	}
+
+	jobId := job.ID
+	scheduler.jobOps.timers[jobId] = time.AfterFunc(next.Sub(time.Now()), func() {
+		scheduler.fireJob(jobId)
+	})
+}
+
+func (scheduler *Scheduler) unscheduleJobLocked(jobId string) {
+	if timer, found := scheduler.jobOps.timers[jobId]; found {
+		timer.Stop()
+		delete(scheduler.jobOps.timers, jobId)
+	}
+}
+
+func (scheduler *Scheduler) fireJob(jobId string) {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	job := store.DB().GetJob(jobId)
+	if job == nil {
+		return
+	}
+
+	// skipped if the previous run is still in progress
+	if _, err := scheduler.startJobRunLocked(job, JOB_RUN_TRIGGER_SCHEDULE); err != nil {
+		logrus.Errorf("start scheduled run of job %s failed: %s", jobId, err.Error())
+	}
+
+	scheduler.scheduleJobLocked(job)
+}
+
+func (scheduler *Scheduler) startJobRunLocked(job *store.Job, trigger string) (*store.JobRun, error) {
+	if scheduler.JobRuns.Get(job.ID) != nil {
+		return nil, errors.New("previous run of job is still in progress")
+	}
+
+	version := state.VersionFromRaft(job.Version)
+	if err := scheduler.checkQuota("", version, int(version.Instances)); err != nil {
+		return nil, err
+	}
+
+	app, err := state.NewJobApp(version, scheduler.userEventChan)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler.JobRuns.Add(app.ID, app)
+
+	run := &store.JobRun{
+		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
+		State:     JOB_RUN_STATE_RUNNING,
+		Trigger:   trigger,
+		StartedAt: time.Now().UnixNano(),
+	}
+
+	job.Runs = append(job.Runs, run)
+	if len(job.Runs) > JOB_RUN_HISTORY_LIMIT {
+		job.Runs = job.Runs[len(job.Runs)-JOB_RUN_HISTORY_LIMIT:]
+	}
+
+	if err := store.DB().UpdateJob(job); err != nil {
+		logrus.Errorf("update job %s failed: %s", job.ID, err.Error())
+	}
+
+	go scheduler.waitJobRun(job.ID, run.ID)
+
+	return run, nil
+}
+
+// poll the app of the run until all its tasks finished, or any of them failed
+// with retries used up
+func (scheduler *Scheduler) waitJobRun(jobId, runId string) {
+	ticker := time.NewTicker(JOB_POLL_INTERVAL)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if scheduler.checkJobRun(jobId, runId) {
+			return
+		}
+	}
+}
+
+// return true if the run is over
+func (scheduler *Scheduler) checkJobRun(jobId, runId string) bool {
+	scheduler.jobOps.Lock()
+	defer scheduler.jobOps.Unlock()
+
+	job := store.DB().GetJob(jobId)
+	if job == nil {
+		return true
+	}
+
+	run := findJobRun(job, runId)
+	if run == nil || run.State != JOB_RUN_STATE_RUNNING {
+		return true
+	}
+
+	app := scheduler.JobRuns.Get(jobId)
+	if app == nil {
+		scheduler.finishJobRunLocked(job, run, JOB_RUN_STATE_FAILED, "tasks of run lost")
+		return true
+	}
+
+	if countJobRunAttempts(run, app) {
+		if err := store.DB().UpdateJob(job); err != nil {
+			logrus.Errorf("update job %s failed: %s", job.ID, err.Error())
+		}
+	}
+
+	finished, failed := jobAppResult(app, run)
+	if !finished {
+		return false
+	}
+
+	run.Tasks = jobRunTasks(app, run)
+	killJobApp(app)
+
+	if len(failed) > 0 {
+		scheduler.finishJobRunLocked(job, run, JOB_RUN_STATE_FAILED,
+			fmt.Sprintf("tasks failed: %s", strings.Join(failed, ", ")))
+	} else {
+		scheduler.finishJobRunLocked(job, run, JOB_RUN_STATE_SUCCEEDED, "")
+	}
+
+	return true
+}
+
+func (scheduler *Scheduler) finishJobRunLocked(job *store.Job, run *store.JobRun, runState, message string) {
+	run.State = runState
+	run.Message = message
+	run.FinishedAt = time.Now().UnixNano()
+
+	if err := store.DB().UpdateJob(job); err != nil {
+		logrus.Errorf("update job %s failed: %s", job.ID, err.Error())
+	}
+}
+
+// the run is over once every task either finished, or failed with no more
+// retries left; ids of failed tasks are returned
+func jobAppResult(app *state.App, run *store.JobRun) (bool, []string) {
+	attempts := jobRunAttempts(run)
+	failed := make([]string, 0)
+
+	for _, slot := range app.GetSlots() {
+		if slot.StateIs(state.SLOT_STATE_TASK_FINISHED) {
+			continue
+		}
+
+		if !slot.Abnormal() {
+			return false, nil
+		}
+
+		if attempts[int32(slot.Index)] <= app.CurrentVersion.MaxRestarts {
+			return false, nil
+		}
+
+		failed = append(failed, slot.ID)
+	}
+
+	return true, failed
+}
+
+// attempts of each task of the run are counted by ids of tasks launched for
+// its slot, and persisted with the run, as task history of slots is pruned
+// and restart policies start over after manager failover
+func countJobRunAttempts(run *store.JobRun, app *state.App) bool {
+	tasks := make(map[int32]*store.JobRunTask)
+	for _, task := range run.Tasks {
+		tasks[task.Index] = task
+	}
+
+	changed := false
+	for _, slot := range app.GetSlots() {
+		if slot.CurrentTask == nil {
+			continue
+		}
+
+		task, found := tasks[int32(slot.Index)]
+		if !found {
+			task = &store.JobRunTask{Index: int32(slot.Index), TaskID: slot.ID}
+			run.Tasks = append(run.Tasks, task)
+		}
+
+		if task.LaunchedTaskID == slot.CurrentTask.ID {
+			continue
+		}
+
+		task.Attempts += launchedSince(slot, task.LaunchedTaskID)
+		task.LaunchedTaskID = slot.CurrentTask.ID
+		changed = true
+	}
+
+	return changed
+}
+
+// tasks launched for the slot after the one seen last, including the current
+// one, those relaunched in between are found in task history
+func launchedSince(slot *state.Slot, lastTaskID string) int32 {
+	for i := len(slot.TaskHistory) - 1; i >= 0; i-- {
+		if slot.TaskHistory[i].ID == lastTaskID {
+			return int32(len(slot.TaskHistory) - i)
+		}
+	}
+
+	if lastTaskID == "" {
+		return int32(len(slot.TaskHistory) + 1)
+	}
+
+	return 1
+}
+
+func jobRunAttempts(run *store.JobRun) map[int32]int32 {
+	attempts := make(map[int32]int32)
+	for _, task := range run.Tasks {
+		attempts[task.Index] = task.Attempts
+	}
+
+	return attempts
+}
+
+func jobRunTasks(app *state.App, run *store.JobRun) []*store.JobRunTask {
+	attempts := jobRunAttempts(run)
+	tasks := make([]*store.JobRunTask, 0)
+
+	for _, slot := range app.GetSlots() {
+		task := &store.JobRunTask{
+			Index:         int32(slot.Index),
+			TaskID:        slot.ID,
+			State:         slot.State,
+			Attempts:      attempts[int32(slot.Index)],
+			AgentHostName: slot.AgentHostName,
+		}
+
+		if slot.CurrentTask != nil {
+			// launched after attempts counted last time
+			if task.Attempts == 0 {
+				task.Attempts = 1
+			}
+			task.LaunchedTaskID = slot.CurrentTask.ID
+			task.Reason = slot.CurrentTask.Reason
+			task.Message = slot.CurrentTask.Message
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks
+}
+
+// tasks not launched yet are taken out of the pending offer queue before the
+// app is deleted
+func killJobApp(app *state.App) {
+	if app.StateIs(state.APP_STATE_DELETING) {
+		return
+	}
+
+	for _, slot := range app.GetSlots() {
+		if slot.StateIs(state.SLOT_STATE_PENDING_OFFER) {
+			state.OfferAllocatorInstance().RemoveSlotFromPendingOfferQueue(slot)
+		}
+	}
+
+	if err := app.Delete(); err != nil {
+		logrus.Errorf("delete app %s of job failed: %s", app.ID, err.Error())
+	}
+}
+
+func findJobRun(job *store.Job, runId string) *store.JobRun {
+	for _, run := range job.Runs {
+		if run.ID == runId {
+			return run
+		}
+	}
+
+	return nil
+}
+
+// the task template of a job turned into the version of its runs
+func jobVersionFromSpec(spec *types.JobSpec) (*types.Version, error) {
+	if spec.Name == "" {
+		return nil, errors.New("name of job required")
+	}
+
+	if spec.Task == nil {
+		return nil, errors.New("task of job required")
+	}
+
+	if spec.Parallelism < 0 {
+		return nil, errors.New("parallelism of job should not be negative")
+	}
+
+	if spec.Retries < 0 {
+		return nil, errors.New("retries of job should not be negative")
+	}
+
+	if spec.Retries >= store.TASK_HISTORY_LIMIT {
+		return nil, fmt.Errorf("retries of job should be less than %d", store.TASK_HISTORY_LIMIT)
+	}
+
+	if spec.Schedule != "" {
+		s, err := parseCronSchedule(spec.Schedule)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.Next(time.Now()).IsZero() {
+			return nil, fmt.Errorf("schedule %s never fires", spec.Schedule)
+		}
+	}
+
+	if spec.Task.HealthCheck != nil {
+		return nil, errors.New("health check not supported by tasks of job")
+	}
+
+	if spec.Task.Container != nil && spec.Task.Container.Docker != nil {
+		network := strings.ToLower(spec.Task.Container.Docker.Network)
+		if network != "host" && network != "bridge" {
+			return nil, errors.New("tasks of job only support host or bridge network")
+		}
+	}
+
+	version := *spec.Task
+	version.AppName = spec.Name
+	version.RunAs = spec.RunAs
+	version.Instances = spec.Parallelism
+	if version.Instances == 0 {
+		version.Instances = 1
+	}
+
+	// failed tasks are relaunched until retries used up
+	version.MaxRestarts = spec.Retries
+	if spec.Retries > 0 {
+		version.RestartPolicy = state.RESTART_POLICY_ON_FAILURE
+	} else {
+		version.RestartPolicy = state.RESTART_POLICY_NEVER
+	}
+
+	if err := state.ValidateVersion(&version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobVersionFromSpec(t *testing.T) {
+	spec := &types.JobSpec{
+		Name:    "backup",
+		RunAs:   "xcm",
+		Retries: 3,
+		Task: &types.Version{
+			CPUs:      0.1,
+			Mem:       32,
+			Container: &types.Container{Docker: &types.Docker{Image: "busybox", Network: "bridge"}},
+		},
+	}
+
+	version, err := jobVersionFromSpec(spec)
+	assert.Nil(t, err)
+	assert.Equal(t, "backup", version.AppName)
+	assert.Equal(t, int32(1), version.Instances)
+	assert.Equal(t, int32(3), version.MaxRestarts)
+	assert.Equal(t, state.RESTART_POLICY_ON_FAILURE, version.RestartPolicy)
+	assert.Equal(t, "", spec.Task.AppName)
+
+	spec.Retries = 0
+	version, err = jobVersionFromSpec(spec)
+	assert.Nil(t, err)
+	assert.Equal(t, state.RESTART_POLICY_NEVER, version.RestartPolicy)
+
+	spec.Retries = store.TASK_HISTORY_LIMIT
+	_, err = jobVersionFromSpec(spec)
+	assert.NotNil(t, err)
+
+	spec.Retries = 0
+	spec.Schedule = "0 0 30 2 *"
+	_, err = jobVersionFromSpec(spec)
+	assert.NotNil(t, err)
+
+	spec.Schedule = ""
+	spec.Task.Container.Docker.Network = "swan"
+	_, err = jobVersionFromSpec(spec)
+	assert.NotNil(t, err)
+}
+
+func TestJobRunAttempts(t *testing.T) {
+	version := &types.Version{MaxRestarts: 2}
+	slot := &state.Slot{ID: "0-backup", Version: version, CurrentTask: &state.Task{ID: "task-0"}}
+	app := &state.App{ID: "backup", CurrentVersion: version, Slots: map[int]*state.Slot{0: slot}}
+	run := &store.JobRun{}
+
+	assert.True(t, countJobRunAttempts(run, app))
+	assert.False(t, countJobRunAttempts(run, app))
+	assert.Equal(t, int32(1), run.Tasks[0].Attempts)
+
+	// relaunched twice between checks
+	slot.TaskHistory = []*state.Task{{ID: "task-0"}, {ID: "task-1"}}
+	slot.CurrentTask = &state.Task{ID: "task-2"}
+	slot.State = state.SLOT_STATE_TASK_FAILED
+	assert.True(t, countJobRunAttempts(run, app))
+	assert.Equal(t, int32(3), run.Tasks[0].Attempts)
+
+	finished, failed := jobAppResult(app, run)
+	assert.True(t, finished)
+	assert.Equal(t, []string{"0-backup"}, failed)
+
+	// attempts are not bounded by pruned task history
+	slot.TaskHistory = []*state.Task{{ID: "task-1"}}
+	version.MaxRestarts = 3
+	finished, _ = jobAppResult(app, run)
+	assert.False(t, finished)
+
+	slot.TaskHistory = []*state.Task{{ID: "task-2"}}
+	slot.CurrentTask = &state.Task{ID: "task-3"}
+	countJobRunAttempts(run, app)
+	finished, _ = jobAppResult(app, run)
+	assert.True(t, finished)
+	assert.Equal(t, int32(4), jobRunTasks(app, run)[0].Attempts)
+}
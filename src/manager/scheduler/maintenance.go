@@ -0,0 +1,178 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/connector"
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
+	"github.com/Dataman-Cloud/swan/src/mesosproto/sched"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// agent going into maintenance, told by an inverse offer
+type maintenance struct {
+	inverseOfferID string
+	// slots on the agent are all moved off by then
+	start time.Time
+}
+
+type maintenances struct {
+	agents map[string]*maintenance
+	sync.Mutex
+}
+
+// slots on the agent are moved elsewhere before its unavailability starts.
+// the inverse offer is declined if any of them can't be moved, and the agent
+// is left as it is
+func (scheduler *Scheduler) scheduleMaintenance(inverseOffer *mesos.InverseOffer) {
+	offerID := inverseOffer.GetId().GetValue()
+	agentID := inverseOffer.GetAgentId().GetValue()
+	if agentID == "" {
+		logrus.Warnf("inverse offer %s for resources of all agents not supported", offerID)
+		DeclineInverseOffer(inverseOffer)
+		return
+	}
+
+	start := time.Unix(0, inverseOffer.GetUnavailability().GetStart().GetNanoseconds())
+
+	pinned := 0
+	for _, app := range scheduler.ListApps(types.AppFilterOptions{}) {
+		for _, slot := range app.GetSlots() {
+			if slot.AgentID == agentID && slot.Dispatched() && !slot.Migratable() {
+				pinned += 1
+			}
+		}
+	}
+
+	if pinned > 0 {
+		logrus.Warnf("agent %s going into maintenance at %s, %d slots can't be moved", agentID, start, pinned)
+		scheduler.maintenances.set(agentID, nil)
+		DeclineInverseOffer(inverseOffer)
+		return
+	}
+
+	logrus.Infof("agent %s going into maintenance at %s, draining", agentID, start)
+	scheduler.maintenances.set(agentID, &maintenance{inverseOfferID: offerID, start: start})
+	AcceptInverseOffer(inverseOffer)
+}
+
+// only agents with inverse offers accepted are drained and get their offers
+// declined, an agent is left as it is once its inverse offer declined, even
+// if accepted before
+func (m *maintenances) set(agentID string, entry *maintenance) {
+	m.Lock()
+	defer m.Unlock()
+
+	if entry == nil {
+		delete(m.agents, agentID)
+		return
+	}
+
+	m.agents[agentID] = entry
+}
+
+// maintenance of the agent is cancelled or over
+func (scheduler *Scheduler) cancelMaintenance(inverseOfferID string) {
+	scheduler.maintenances.Lock()
+	defer scheduler.maintenances.Unlock()
+
+	for agentID, m := range scheduler.maintenances.agents {
+		if m.inverseOfferID == inverseOfferID {
+			logrus.Infof("inverse offer %s rescinded, agent %s is not going into maintenance", inverseOfferID, agentID)
+			delete(scheduler.maintenances.agents, agentID)
+		}
+	}
+}
+
+func (scheduler *Scheduler) underMaintenance(agentID string) bool {
+	scheduler.maintenances.Lock()
+	defer scheduler.maintenances.Unlock()
+
+	_, found := scheduler.maintenances.agents[agentID]
+	return found
+}
+
+// called on each heartbeat, slots on agents going into maintenance are
+// moved off gradually
+func (scheduler *Scheduler) drainAgentsForMaintenance() {
+	starts := make(map[string]time.Time)
+	scheduler.maintenances.Lock()
+	for agentID, m := range scheduler.maintenances.agents {
+		starts[agentID] = m.start
+	}
+	scheduler.maintenances.Unlock()
+
+	if len(starts) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, app := range scheduler.ListApps(types.AppFilterOptions{}) {
+		slots := app.GetSlots()
+
+		candidates := make([]*state.Slot, 0)
+		for _, slot := range slots {
+			if _, found := starts[slot.AgentID]; found && slot.Dispatched() && slot.Migratable() && !slot.Migrating() {
+				candidates = append(candidates, slot)
+			}
+		}
+
+		for _, slot := range selectMigrations(slots, candidates, starts, now) {
+			slot.Migrate(slot.AgentID)
+		}
+	}
+}
+
+// one candidate of an app is moved at a time, only while all slots of the app
+// are running, the rest are moved at once when maintenance of their agent starts
+func selectMigrations(slots, candidates []*state.Slot, starts map[string]time.Time, now time.Time) []*state.Slot {
+	settled := true
+	for _, slot := range slots {
+		if !slot.StateIs(state.SLOT_STATE_TASK_RUNNING) || slot.Migrating() {
+			settled = false
+		}
+	}
+
+	migrations := make([]*state.Slot, 0)
+	for _, slot := range candidates {
+		if now.Before(starts[slot.AgentID]) && (!settled || len(migrations) > 0) {
+			continue
+		}
+
+		migrations = append(migrations, slot)
+	}
+
+	return migrations
+}
+
+func AcceptInverseOffer(inverseOffer *mesos.InverseOffer) {
+	call := &sched.Call{
+		FrameworkId: connector.Instance().FrameworkInfo.GetId(),
+		Type:        sched.Call_ACCEPT_INVERSE_OFFERS.Enum(),
+		AcceptInverseOffers: &sched.Call_AcceptInverseOffers{
+			InverseOfferIds: []*mesos.OfferID{
+				inverseOffer.GetId(),
+			},
+		},
+	}
+
+	connector.Instance().SendCall(call)
+}
+
+func DeclineInverseOffer(inverseOffer *mesos.InverseOffer) {
+	call := &sched.Call{
+		FrameworkId: connector.Instance().FrameworkInfo.GetId(),
+		Type:        sched.Call_DECLINE_INVERSE_OFFERS.Enum(),
+		DeclineInverseOffers: &sched.Call_DeclineInverseOffers{
+			InverseOfferIds: []*mesos.OfferID{
+				inverseOffer.GetId(),
+			},
+		},
+	}
+
+	connector.Instance().SendCall(call)
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func slotOnAgent(id, agent, slotState string) *state.Slot {
+	return &state.Slot{ID: id, AgentID: agent, State: slotState}
+}
+
+func TestSelectMigrations(t *testing.T) {
+	now := time.Now()
+	starts := map[string]time.Time{
+		"agent-a": now.Add(time.Hour),
+		"agent-b": now.Add(-time.Minute),
+	}
+
+	a0 := slotOnAgent("a-0", "agent-a", state.SLOT_STATE_TASK_RUNNING)
+	a1 := slotOnAgent("a-1", "agent-a", state.SLOT_STATE_TASK_RUNNING)
+	c0 := slotOnAgent("c-0", "agent-c", state.SLOT_STATE_TASK_RUNNING)
+
+	// one at a time before maintenance starts
+	migrations := selectMigrations([]*state.Slot{a0, a1, c0}, []*state.Slot{a0, a1}, starts, now)
+	assert.Equal(t, []*state.Slot{a0}, migrations)
+
+	// waiting for the previous one relaunched
+	c0.State = state.SLOT_STATE_PENDING_OFFER
+	migrations = selectMigrations([]*state.Slot{a0, a1, c0}, []*state.Slot{a0, a1}, starts, now)
+	assert.Len(t, migrations, 0)
+
+	// all at once after maintenance started
+	b0 := slotOnAgent("b-0", "agent-b", state.SLOT_STATE_TASK_RUNNING)
+	b1 := slotOnAgent("b-1", "agent-b", state.SLOT_STATE_TASK_RUNNING)
+	migrations = selectMigrations([]*state.Slot{b0, b1, c0}, []*state.Slot{b0, b1}, starts, now)
+	assert.Equal(t, []*state.Slot{b0, b1}, migrations)
+}
+
+func TestMaintenancesOfDeclinedInverseOffers(t *testing.T) {
+	scheduler := &Scheduler{maintenances: &maintenances{agents: make(map[string]*maintenance)}}
+
+	scheduler.maintenances.set("agent-a", &maintenance{inverseOfferID: "inverse-0", start: time.Now()})
+	assert.True(t, scheduler.underMaintenance("agent-a"))
+
+	// declined later, as slots which can't be moved got launched on it
+	scheduler.maintenances.set("agent-a", nil)
+	assert.False(t, scheduler.underMaintenance("agent-a"))
+
+	scheduler.maintenances.set("agent-b", &maintenance{inverseOfferID: "inverse-1", start: time.Now()})
+	scheduler.cancelMaintenance("inverse-1")
+	assert.False(t, scheduler.underMaintenance("agent-b"))
+}
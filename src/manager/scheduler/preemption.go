@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// slots waiting for offers longer than preemption delay kill running tasks of
+// lower priority apps on a single agent, which would free enough resources.
+// slots with constraints are skipped, as agents matching them are not known
+// until offered
+func (scheduler *Scheduler) preemptForPendingSlots() {
+	if scheduler.preemptionDelay <= 0 {
+		return
+	}
+
+	pending := state.OfferAllocatorInstance().PendingSlots()
+
+	candidates := make([]*state.Slot, 0)
+	for _, app := range scheduler.ListApps(types.AppFilterOptions{}) {
+		for _, slot := range app.GetSlots() {
+			if slot.Preemptible() {
+				candidates = append(candidates, slot)
+			}
+		}
+	}
+
+	waiting := make(map[string]bool)
+	for _, slot := range pending {
+		waiting[slot.ID] = true
+
+		if slot.CurrentTask == nil || slot.Version.Constraints != "" ||
+			time.Since(slot.CurrentTask.Created) < scheduler.preemptionDelay {
+			continue
+		}
+
+		// victims of last preemption might be still being killed
+		if last, found := scheduler.preemptions[slot.ID]; found && time.Since(last) < scheduler.preemptionDelay {
+			continue
+		}
+
+		victims := selectVictims(slot, candidates)
+		if len(victims) == 0 {
+			continue
+		}
+
+		logrus.Infof("slot %s pending for %v, preempting %d tasks", slot.ID, time.Since(slot.CurrentTask.Created), len(victims))
+
+		preempted := make(map[*state.Slot]bool)
+		for _, victim := range victims {
+			victim.Preempt(slot)
+			preempted[victim] = true
+		}
+		scheduler.preemptions[slot.ID] = time.Now()
+
+		remained := make([]*state.Slot, 0)
+		for _, candidate := range candidates {
+			if !preempted[candidate] {
+				remained = append(remained, candidate)
+			}
+		}
+		candidates = remained
+	}
+
+	for id := range scheduler.preemptions {
+		if !waiting[id] {
+			delete(scheduler.preemptions, id)
+		}
+	}
+}
+
+// fewest tasks of lower priority on a single agent to free resources needed,
+// tasks of the lowest priority and launched latest are preempted first
+func selectVictims(pending *state.Slot, candidates []*state.Slot) []*state.Slot {
+	byAgent := make(map[string][]*state.Slot)
+	for _, slot := range candidates {
+		if slot.Version.Priority < pending.Version.Priority {
+			byAgent[slot.AgentID] = append(byAgent[slot.AgentID], slot)
+		}
+	}
+
+	agents := make([]string, 0)
+	for agent := range byAgent {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	var victims []*state.Slot
+	for _, agent := range agents {
+		slots := byAgent[agent]
+		sort.SliceStable(slots, func(i, j int) bool {
+			if slots[i].Version.Priority != slots[j].Version.Priority {
+				return slots[i].Version.Priority < slots[j].Version.Priority
+			}
+			return slots[i].CurrentTask.Created.After(slots[j].CurrentTask.Created)
+		})
+
+		var cpus, mem, disk float64
+		for i, slot := range slots {
+			cpus += slot.Version.CPUs
+			mem += slot.Version.Mem
+			disk += slot.Version.Disk
+
+			if cpus >= pending.Version.CPUs && mem >= pending.Version.Mem && disk >= pending.Version.Disk {
+				if victims == nil || i+1 < len(victims) {
+					victims = slots[:i+1]
+				}
+				break
+			}
+		}
+	}
+
+	return victims
+}
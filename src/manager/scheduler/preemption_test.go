@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runningSlot(id, agent string, priority int32, cpus, mem float64, launched time.Time) *state.Slot {
+	return &state.Slot{
+		ID:          id,
+		AgentID:     agent,
+		Version:     &types.Version{Priority: priority, CPUs: cpus, Mem: mem},
+		CurrentTask: &state.Task{Created: launched},
+	}
+}
+
+func TestSelectVictims(t *testing.T) {
+	now := time.Now()
+	pending := runningSlot("critical", "", 10, 2, 512, now)
+
+	candidates := []*state.Slot{
+		runningSlot("a-old", "agent-a", 0, 1, 256, now.Add(-time.Hour)),
+		runningSlot("a-new", "agent-a", 0, 1, 256, now),
+		runningSlot("a-mid", "agent-a", 5, 1, 256, now),
+		runningSlot("b-big", "agent-b", 5, 4, 1024, now),
+		runningSlot("c-high", "agent-c", 10, 4, 1024, now),
+	}
+
+	victims := selectVictims(pending, candidates)
+	assert.Len(t, victims, 1)
+	assert.Equal(t, "b-big", victims[0].ID)
+
+	// the lowest priority and latest launched first
+	victims = selectVictims(pending, candidates[:3])
+	assert.Len(t, victims, 2)
+	assert.Equal(t, "a-new", victims[0].ID)
+	assert.Equal(t, "a-old", victims[1].ID)
+
+	// slots of the same or higher priority are never preempted
+	assert.Len(t, selectVictims(pending, candidates[4:]), 0)
+
+	pending.Version.CPUs = 8
+	assert.Len(t, selectVictims(pending, candidates), 0)
+}
@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+)
+
+func (scheduler *Scheduler) CreateQuota(quota *types.Quota) (*store.Quota, error) {
+	if err := validateQuota(quota); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixNano()
+	raftQuota := &store.Quota{
+		RunAs:     quota.RunAs,
+		Cpus:      quota.CPUs,
+		Mem:       quota.Mem,
+		Disk:      quota.Disk,
+		Instances: quota.Instances,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := store.DB().CreateQuota(raftQuota); err != nil {
+		return nil, err
+	}
+
+	return raftQuota, nil
+}
+
+func (scheduler *Scheduler) InspectQuota(runAs string) (*store.Quota, error) {
+	quota := store.DB().GetQuota(runAs)
+	if quota == nil {
+		return nil, errors.New("quota not exists")
+	}
+
+	return quota, nil
+}
+
+func (scheduler *Scheduler) ListQuotas() []*store.Quota {
+	return store.DB().ListQuotas()
+}
+
+// quota lower than current usage only stops apps of the user from growing,
+// nothing running is killed
+func (scheduler *Scheduler) UpdateQuota(runAs string, quota *types.Quota) (*store.Quota, error) {
+	old, err := scheduler.InspectQuota(runAs)
+	if err != nil {
+		return nil, err
+	}
+
+	quota.RunAs = runAs
+	if err := validateQuota(quota); err != nil {
+		return nil, err
+	}
+
+	raftQuota := &store.Quota{
+		RunAs:     runAs,
+		Cpus:      quota.CPUs,
+		Mem:       quota.Mem,
+		Disk:      quota.Disk,
+		Instances: quota.Instances,
+		CreatedAt: old.CreatedAt,
+		UpdatedAt: time.Now().UnixNano(),
+	}
+
+	if err := store.DB().UpdateQuota(raftQuota); err != nil {
+		return nil, err
+	}
+
+	return raftQuota, nil
+}
+
+func (scheduler *Scheduler) DeleteQuota(runAs string) error {
+	return store.DB().DeleteQuota(runAs)
+}
+
+// resources of all slots of apps and job runs run as the user, excluding the
+// app specified
+func (scheduler *Scheduler) QuotaUsage(runAs, excludeAppID string) *types.QuotaUsage {
+	apps := scheduler.ListApps(types.AppFilterOptions{})
+	apps = append(apps, scheduler.JobRuns.Filter(types.AppFilterOptions{})...)
+
+	usage := &types.QuotaUsage{}
+	for _, app := range apps {
+		if app.ID == excludeAppID || app.CurrentVersion == nil || app.CurrentVersion.RunAs != runAs {
+			continue
+		}
+
+		for _, slot := range app.GetSlots() {
+			usage.CPUs += slot.Version.CPUs
+			usage.Mem += slot.Version.Mem
+			usage.Disk += slot.Version.Disk
+			usage.Instances += 1
+		}
+	}
+
+	return usage
+}
+
+// check if instances of version more would exceed quota of the user, apps of
+// the user excluding the one specified are counted in
+func (scheduler *Scheduler) checkQuota(excludeAppID string, version *types.Version, instances int) error {
+	quota := store.DB().GetQuota(version.RunAs)
+	if quota == nil {
+		return nil
+	}
+
+	usage := scheduler.QuotaUsage(version.RunAs, excludeAppID)
+	usage.CPUs += version.CPUs * float64(instances)
+	usage.Mem += version.Mem * float64(instances)
+	usage.Disk += version.Disk * float64(instances)
+	usage.Instances += int32(instances)
+
+	return quotaExceeded(quota, usage)
+}
+
+func quotaExceeded(quota *store.Quota, usage *types.QuotaUsage) error {
+	exceeded := func(name string, used, limit float64) error {
+		return fmt.Errorf("quota of %s exceeded: %s %v more than %v", quota.RunAs, name, used, limit)
+	}
+
+	switch {
+	case quota.Cpus > 0 && usage.CPUs > quota.Cpus:
+		return exceeded("cpus", usage.CPUs, quota.Cpus)
+	case quota.Mem > 0 && usage.Mem > quota.Mem:
+		return exceeded("mem", usage.Mem, quota.Mem)
+	case quota.Disk > 0 && usage.Disk > quota.Disk:
+		return exceeded("disk", usage.Disk, quota.Disk)
+	case quota.Instances > 0 && usage.Instances > quota.Instances:
+		return exceeded("instances", float64(usage.Instances), float64(quota.Instances))
+	}
+
+	return nil
+}
+
+func validateQuota(quota *types.Quota) error {
+	if quota.RunAs == "" {
+		return errors.New("runAs required")
+	}
+
+	if quota.CPUs < 0 || quota.Mem < 0 || quota.Disk < 0 || quota.Instances < 0 {
+		return errors.New("limits of quota can not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaExceeded(t *testing.T) {
+	quota := &store.Quota{RunAs: "xcm", Cpus: 4, Instances: 10}
+
+	assert.Nil(t, quotaExceeded(quota, &types.QuotaUsage{CPUs: 4, Mem: 65536, Instances: 10}))
+
+	err := quotaExceeded(quota, &types.QuotaUsage{CPUs: 4.5, Instances: 5})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cpus")
+
+	err = quotaExceeded(quota, &types.QuotaUsage{CPUs: 1, Instances: 11})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "instances")
+}
+
+func TestValidateQuota(t *testing.T) {
+	assert.Nil(t, validateQuota(&types.Quota{RunAs: "xcm", CPUs: 4}))
+	assert.NotNil(t, validateQuota(&types.Quota{CPUs: 4}))
+	assert.NotNil(t, validateQuota(&types.Quota{RunAs: "xcm", Mem: -1}))
+}
+
+func TestQuotaUsageCountsJobRuns(t *testing.T) {
+	scheduler := &Scheduler{AppStorage: NewMemoryStore(), JobRuns: NewMemoryStore()}
+
+	quotaApp := func(id string, cpus float64, instances int) *state.App {
+		version := &types.Version{RunAs: "xcm", CPUs: cpus}
+		app := &state.App{ID: id, CurrentVersion: version, Slots: make(map[int]*state.Slot)}
+		for i := 0; i < instances; i++ {
+			app.Slots[i] = &state.Slot{ID: fmt.Sprintf("%d-%s", i, id), Version: version}
+		}
+		return app
+	}
+
+	scheduler.AppStorage.Add("web", quotaApp("web", 1, 2))
+	scheduler.JobRuns.Add("backup", quotaApp("backup", 0.5, 2))
+
+	usage := scheduler.QuotaUsage("xcm", "")
+	assert.Equal(t, 3.0, usage.CPUs)
+	assert.Equal(t, int32(4), usage.Instances)
+
+	usage = scheduler.QuotaUsage("xcm", "web")
+	assert.Equal(t, 1.0, usage.CPUs)
+	assert.Equal(t, int32(2), usage.Instances)
+}
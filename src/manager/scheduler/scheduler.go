@@ -29,18 +29,41 @@ type Scheduler struct {
 
 	AppStorage     *memoryStore
 	MesosConnector *connector.Connector
+
+	// apps carrying out runs of jobs, by job id
+	JobRuns *memoryStore
+
+	groupOps *groupOperations
+	jobOps   *jobOperations
+
+	// pending slots waiting longer than this preempt tasks of lower priority
+	preemptionDelay time.Duration
+	// last preemption by pending slot id
+	preemptions map[string]time.Time
+
+	maintenances *maintenances
 }
 
 func NewScheduler(mConfig config.ManagerConfig) *Scheduler {
 	connector.Init(mConfig.MesosFrameworkUser, mConfig.MesosZkPath)
+	connector.Instance().SetOfferFilters(mConfig.OfferRefuseSeconds, mConfig.OfferMaxRefuseSeconds)
+	state.SetGatewayMetricsAddrs(mConfig.GatewayMetricsAddrs)
 
 	scheduler := &Scheduler{
 		MesosConnector: connector.Instance(),
 		heartbeater:    time.NewTicker(10 * time.Second),
 
 		AppStorage: NewMemoryStore(),
+		JobRuns:    NewMemoryStore(),
+		groupOps:   &groupOperations{ops: make(map[string]*groupOperation)},
+		jobOps:     &jobOperations{timers: make(map[string]*time.Timer)},
 
 		userEventChan: make(chan *event.UserEvent, 1024),
+
+		preemptionDelay: mConfig.PreemptionDelay,
+		preemptions:     make(map[string]time.Time),
+
+		maintenances: &maintenances{agents: make(map[string]*maintenance)},
 	}
 
 	scheduler.handlerManager = NewHandlerManager(scheduler)
@@ -92,6 +115,9 @@ func (scheduler *Scheduler) Start(ctx context.Context) error {
 
 		case <-scheduler.heartbeater.C: // heartbeat timeout for now
 			logrus.WithFields(logrus.Fields{"event": "heartBeat"}).Debugln("heart beat package")
+			scheduler.preemptForPendingSlots()
+			scheduler.drainAgentsForMaintenance()
+			scheduler.reviveIfPending()
 
 		case <-ctx.Done():
 			logrus.Info("scheduler shutdown  goroutine by ctx cancel")
@@ -108,7 +134,11 @@ func (scheduler *Scheduler) recoverFromPreviousScene() error {
 
 	apps := state.LoadAppData(scheduler.userEventChan)
 	for _, app := range apps {
-		scheduler.AppStorage.Add(app.ID, app)
+		if app.Job {
+			scheduler.JobRuns.Add(app.ID, app)
+		} else {
+			scheduler.AppStorage.Add(app.ID, app)
+		}
 
 		for _, slot := range app.GetSlots() {
 			if slot.StateIs(state.SLOT_STATE_PENDING_OFFER) {
@@ -122,6 +152,9 @@ func (scheduler *Scheduler) recoverFromPreviousScene() error {
 		return err
 	}
 
+	scheduler.recoverGroups()
+	scheduler.recoverJobs()
+
 	return nil
 }
 
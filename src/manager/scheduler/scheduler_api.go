@@ -6,6 +6,7 @@ import (
 
 	"github.com/Dataman-Cloud/swan/src/manager/connector"
 	"github.com/Dataman-Cloud/swan/src/manager/state"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
 	"github.com/Dataman-Cloud/swan/src/types"
 )
 
@@ -15,6 +16,14 @@ func (scheduler *Scheduler) CreateApp(version *types.Version) (*state.App, error
 		return nil, errors.New("app already exists")
 	}
 
+	if store.DB().GetJob(appID) != nil {
+		return nil, errors.New("job with the same name already exists")
+	}
+
+	if err := scheduler.checkQuota("", version, int(version.Instances)); err != nil {
+		return nil, err
+	}
+
 	app, err := state.NewApp(version, scheduler.userEventChan)
 	if err != nil {
 		return nil, err
@@ -57,15 +66,24 @@ func (scheduler *Scheduler) ScaleUp(appId string, newInstances int, newIps []str
 		return errors.New("app not exists")
 	}
 
+	// new slots are launched with the current version
+	if err := scheduler.checkQuota("", app.CurrentVersion, newInstances); err != nil {
+		return err
+	}
+
 	return app.ScaleUp(newInstances, newIps)
 }
 
-func (scheduler *Scheduler) ScaleDown(appId string, removeInstances int) error {
+func (scheduler *Scheduler) ScaleDown(appId string, removeInstances int, indices []int, ips []string) error {
 	app := scheduler.AppStorage.Get(appId)
 	if app == nil {
 		return errors.New("app not exists")
 	}
 
+	if len(indices) > 0 || len(ips) > 0 {
+		return app.ScaleDownSlots(removeInstances, indices, ips)
+	}
+
 	return app.ScaleDown(removeInstances)
 }
 
@@ -75,9 +93,39 @@ func (scheduler *Scheduler) UpdateApp(appId string, version *types.Version) erro
 		return errors.New("app not exists")
 	}
 
+	// instances can not change by update
+	if err := scheduler.checkQuota(appId, version, len(app.GetSlots())); err != nil {
+		return err
+	}
+
 	return app.Update(version)
 }
 
+func (scheduler *Scheduler) PreviewUpdateApp(appId string, version *types.Version) (*types.UpdatePreview, error) {
+	app := scheduler.AppStorage.Get(appId)
+	if app == nil {
+		return nil, errors.New("app not exists")
+	}
+
+	return app.PreviewUpdate(version), nil
+}
+
+func (scheduler *Scheduler) RollbackApp(appId string, versionId string) error {
+	app := scheduler.AppStorage.Get(appId)
+	if app == nil {
+		return errors.New("app not exists")
+	}
+
+	// instances follow the current version on rollback
+	if target := app.LookupVersion(versionId); target != nil {
+		if err := scheduler.checkQuota(appId, target, len(app.GetSlots())); err != nil {
+			return err
+		}
+	}
+
+	return app.Rollback(versionId)
+}
+
 func (scheduler *Scheduler) CancelUpdate(appId string) error {
 	app := scheduler.AppStorage.Get(appId)
 	if app == nil {
@@ -87,6 +135,42 @@ func (scheduler *Scheduler) CancelUpdate(appId string) error {
 	return app.CancelUpdate()
 }
 
+func (scheduler *Scheduler) SuspendApp(appId string) error {
+	app := scheduler.AppStorage.Get(appId)
+	if app == nil {
+		return errors.New("app not exists")
+	}
+
+	return app.Suspend()
+}
+
+func (scheduler *Scheduler) ResumeApp(appId string) error {
+	app := scheduler.AppStorage.Get(appId)
+	if app == nil {
+		return errors.New("app not exists")
+	}
+
+	return app.Resume()
+}
+
+func (scheduler *Scheduler) RestartApp(appId string) error {
+	app := scheduler.AppStorage.Get(appId)
+	if app == nil {
+		return errors.New("app not exists")
+	}
+
+	return app.Restart()
+}
+
+func (scheduler *Scheduler) RestartTask(appId string, index int) error {
+	app := scheduler.AppStorage.Get(appId)
+	if app == nil {
+		return errors.New("app not exists")
+	}
+
+	return app.RestartSlot(index)
+}
+
 func (scheduler *Scheduler) ProceedUpdate(appId string, instances int, newWeights map[string]float64) error {
 	app := scheduler.AppStorage.Get(appId)
 	if app == nil {
@@ -40,6 +40,8 @@ type App struct {
 	CurrentVersion *types.Version `json:"current_version"`
 	// use when app updated, ProposedVersion can either be commit or revert
 	ProposedVersion *types.Version `json:"proposed_version"`
+	// history of rollbacks applied to the app
+	Rollbacks []*types.Rollback `json:"rollbacks"`
 
 	Mode AppMode `json:"mode"` // fixed or repliactes
 
@@ -49,6 +51,9 @@ type App struct {
 	StateMachine *StateMachine
 	ClusterID    string
 
+	// app carrying out a run of a job, its tasks run to completion
+	Job bool `json:"job"`
+
 	UserEventChan chan *event.UserEvent
 }
 
@@ -61,6 +66,53 @@ func (a AppsByUpdated) Less(i, j int) bool { return a[i].Updated.After(a[j].Upda
 func NewApp(version *types.Version,
 	userEventChan chan *event.UserEvent) (*App, error) {
 
+	app, err := newApp(version, userEventChan)
+	if err != nil {
+		return nil, err
+	}
+
+	app.create()
+
+	app.StateMachine = NewStateMachine()
+	app.StateMachine.Start(NewStateCreating(app))
+
+	app.SaveVersion(app.CurrentVersion)
+
+	return app, nil
+}
+
+// all slots of the app are launched at once, slots are standby as tasks of
+// a job are never exposed by gateway or dns
+func NewJobApp(version *types.Version,
+	userEventChan chan *event.UserEvent) (*App, error) {
+
+	app, err := newApp(version, userEventChan)
+	if err != nil {
+		return nil, err
+	}
+
+	app.Job = true
+
+	app.create()
+
+	app.StateMachine = NewStateMachine()
+	app.StateMachine.Start(NewStateNormal(app))
+
+	app.SaveVersion(app.CurrentVersion)
+
+	for i := 0; i < int(version.Instances); i++ {
+		slot := NewSlot(app, app.CurrentVersion, i)
+		slot.standby = true
+		app.SetSlot(i, slot)
+		slot.DispatchNewTask(slot.Version)
+	}
+
+	return app, nil
+}
+
+func newApp(version *types.Version,
+	userEventChan chan *event.UserEvent) (*App, error) {
+
 	err := validateAndFormatVersion(version)
 	if err != nil {
 		return nil, err
@@ -68,6 +120,7 @@ func NewApp(version *types.Version,
 
 	app := &App{
 		Versions:       []*types.Version{},
+		Rollbacks:      []*types.Rollback{},
 		Slots:          make(map[int]*Slot),
 		CurrentVersion: version,
 		ID:             fmt.Sprintf("%s-%s-%s", version.AppName, version.RunAs, connector.Instance().ClusterID),
@@ -89,13 +142,6 @@ func NewApp(version *types.Version,
 		version.AppVersion = version.ID
 	}
 
-	app.create()
-
-	app.StateMachine = NewStateMachine()
-	app.StateMachine.Start(NewStateCreating(app))
-
-	app.SaveVersion(app.CurrentVersion)
-
 	return app, nil
 }
 
@@ -145,6 +191,81 @@ func (app *App) ScaleDown(removeInstances int) error {
 	return app.TransitTo(APP_STATE_SCALE_DOWN)
 }
 
+// remove chosen slots, identified by index or by ip for app in fixed mode,
+// slots left are re-mapped so that indices stay contiguous. removeInstances,
+// if given, must match the count of slots chosen, a slot given both by index
+// and by ip counted once
+func (app *App) ScaleDownSlots(removeInstances int, indices []int, ips []string) error {
+	if !app.StateMachine.CanTransitTo(APP_STATE_SCALE_DOWN) {
+		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
+			app.StateMachine.ReadableState(), APP_STATE_SCALE_DOWN)
+	}
+
+	if len(ips) > 0 && !app.IsFixed() {
+		return errors.New("scale down by ip only supported by app in fixed mode")
+	}
+
+	chosen, err := resolveSlots(app.GetSlots(), indices, ips)
+	if err != nil {
+		return err
+	}
+
+	if len(chosen) == 0 {
+		return errors.New("please specify at least 1 task to scale-down")
+	}
+
+	if removeInstances > 0 && removeInstances != len(chosen) {
+		return fmt.Errorf("instances %d not match count of slots specified: %d", removeInstances, len(chosen))
+	}
+
+	app.CurrentVersion.Instances = int32(len(app.Slots) - len(chosen))
+	app.Updated = time.Now()
+
+	app.Touch()
+
+	return app.TransitTo(APP_STATE_SCALE_DOWN, chosen)
+}
+
+// indices of slots given by index or by ip, each slot once in the order given
+func resolveSlots(slots []*Slot, indices []int, ips []string) ([]int, error) {
+	exists := make(map[int]bool)
+	for _, slot := range slots {
+		exists[slot.Index] = true
+	}
+
+	chosen := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, index := range indices {
+		if !exists[index] {
+			return nil, fmt.Errorf("slot not found: %d", index)
+		}
+
+		if !seen[index] {
+			seen[index] = true
+			chosen = append(chosen, index)
+		}
+	}
+
+	for _, ip := range ips {
+		found := false
+		for _, slot := range slots {
+			if slot.Ip == ip {
+				found = true
+				if !seen[slot.Index] {
+					seen[slot.Index] = true
+					chosen = append(chosen, slot.Index)
+				}
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no slot with ip %s", ip)
+		}
+	}
+
+	return chosen, nil
+}
+
 // delete a application and all related objects: versions, tasks, slots, proxies, dns record
 func (app *App) Delete() error {
 	if !app.StateMachine.CanTransitTo(APP_STATE_DELETING) {
@@ -155,6 +276,73 @@ func (app *App) Delete() error {
 	return app.TransitTo(APP_STATE_DELETING)
 }
 
+// kill all tasks of the app, slots with their versions, ips and weights are kept
+func (app *App) Suspend() error {
+	if !app.StateIs(APP_STATE_NORMAL) {
+		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
+			app.StateMachine.ReadableState(), APP_STATE_SUSPENDED)
+	}
+
+	return app.TransitTo(APP_STATE_SUSPENDED)
+}
+
+// launch tasks of all slots again with current version
+func (app *App) Resume() error {
+	if !app.StateIs(APP_STATE_SUSPENDED) {
+		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
+			app.StateMachine.ReadableState(), APP_STATE_NORMAL)
+	}
+
+	for _, slot := range app.GetSlots() {
+		if !slot.StateIs(SLOT_STATE_REAP) && !slot.Abnormal() {
+			return errors.New("tasks of app are being killed, try again later")
+		}
+	}
+
+	if err := app.TransitTo(APP_STATE_NORMAL); err != nil {
+		return err
+	}
+
+	for _, slot := range app.GetSlots() {
+		if slot.CurrentTask != nil {
+			slot.Archive()
+		}
+
+		slot.DispatchNewTask(app.CurrentVersion)
+		slot.StartRestartPolicy()
+	}
+
+	return nil
+}
+
+// restart tasks of all slots one by one with current version
+func (app *App) Restart() error {
+	if !app.StateIs(APP_STATE_NORMAL) {
+		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
+			app.StateMachine.ReadableState(), APP_STATE_RESTARTING)
+	}
+
+	if len(app.GetSlots()) == 0 {
+		return errors.New("no task to restart")
+	}
+
+	return app.TransitTo(APP_STATE_RESTARTING, 0, len(app.GetSlots())-1)
+}
+
+// kill task of a single slot and launch it again with current version
+func (app *App) RestartSlot(index int) error {
+	if !app.StateIs(APP_STATE_NORMAL) {
+		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
+			app.StateMachine.ReadableState(), APP_STATE_RESTARTING)
+	}
+
+	if _, found := app.GetSlot(index); !found {
+		return fmt.Errorf("slot not found: %d", index)
+	}
+
+	return app.TransitTo(APP_STATE_RESTARTING, index, index)
+}
+
 func (app *App) Update(version *types.Version) error {
 	if !app.StateMachine.CanTransitTo(APP_STATE_UPDATING) || app.ProposedVersion != nil {
 		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
@@ -170,20 +358,102 @@ func (app *App) Update(version *types.Version) error {
 		version.AppVersion = version.ID
 	}
 
+	if app.CurrentVersion == nil {
+		return errors.New("update failed: current version was losted")
+	}
+
+	// appVersion should not equal
+	if version.AppVersion == app.CurrentVersion.AppVersion {
+		return fmt.Errorf("app version %s exists, choose another one", version.AppVersion)
+	}
+
 	if err := app.checkProposedVersionValid(version); err != nil {
 		return err
 	}
 
+	app.ProposedVersion = version
+
+	app.SaveVersion(app.ProposedVersion)
+	app.Touch()
+
+	return app.beginUpdate()
+}
+
+// rollback redeploys a previously saved version through the rolling update flow,
+// the version keeps its original ID so slots can be traced back to it.
+func (app *App) Rollback(versionID string) error {
+	if !app.StateMachine.CanTransitTo(APP_STATE_UPDATING) || app.ProposedVersion != nil {
+		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
+			app.StateMachine.ReadableState(), APP_STATE_UPDATING)
+	}
+
 	if app.CurrentVersion == nil {
-		return errors.New("update failed: current version was losted")
+		return errors.New("rollback failed: current version was losted")
 	}
 
-	app.ProposedVersion = version
+	if versionID == app.CurrentVersion.ID {
+		return fmt.Errorf("version %s is the current version", versionID)
+	}
+
+	target := app.LookupVersion(versionID)
+	if target == nil {
+		return fmt.Errorf("version %s not found", versionID)
+	}
+
+	// work on a copy, instances and ips should follow the current version as
+	// the app may have been scaled since the target version was saved
+	version := *target
+	version.Instances = app.CurrentVersion.Instances
+	if app.IsFixed() {
+		version.IP = make([]string, len(app.CurrentVersion.IP))
+		copy(version.IP, app.CurrentVersion.IP)
+	}
+
+	if err := app.checkProposedVersionValid(&version); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	app.Rollbacks = append(app.Rollbacks, &types.Rollback{
+		ID:            fmt.Sprintf("%d", now.UnixNano()),
+		FromVersionID: app.CurrentVersion.ID,
+		ToVersionID:   version.ID,
+		Created:       now,
+	})
+
+	app.ProposedVersion = &version
+	app.Updated = now
 
-	app.SaveVersion(app.ProposedVersion)
 	app.Touch()
 
-	return app.TransitTo(APP_STATE_UPDATING, 1)
+	return app.beginUpdate()
+}
+
+// start updating to ProposedVersion with strategy of its update policy
+func (app *App) beginUpdate() error {
+	if policy := app.ProposedVersion.UpdatePolicy; policy != nil && policy.Strategy == UPDATE_STRATEGY_BLUE_GREEN {
+		return app.TransitTo(APP_STATE_BLUE_GREEN)
+	}
+
+	return app.TransitTo(APP_STATE_UPDATING, app.firstBatchSize())
+}
+
+// count of slots updated on the first step of rolling update
+func (app *App) firstBatchSize() int {
+	if canaries := canaryInstances(app.ProposedVersion); canaries > 0 {
+		return canaries
+	}
+
+	policy := app.ProposedVersion.UpdatePolicy
+	if policy == nil || policy.BatchSize <= 0 {
+		return 1
+	}
+
+	if int(policy.BatchSize) > len(app.Slots) {
+		return len(app.Slots)
+	}
+
+	return int(policy.BatchSize)
 }
 
 func (app *App) ProceedingRollingUpdate(instances int, newWeights map[string]float64) error {
@@ -216,20 +486,34 @@ func (app *App) ProceedingRollingUpdate(instances int, newWeights map[string]flo
 		return errors.New("previous update not completed, abort")
 	}
 
+	weights := make(map[int]float64)
 	for index, newWeight := range newWeights {
 		indexInt, err := strconv.Atoi(index)
 		if err != nil {
 			return errors.New(fmt.Sprintf("fails to update weight, error:%s", err.Error()))
 		}
-		if slot, ok := app.Slots[indexInt]; ok {
+		weights[indexInt] = newWeight
+	}
+
+	// weights applied after transition as leaving canary state resets them
+	if err := app.TransitTo(APP_STATE_UPDATING, instances); err != nil {
+		return err
+	}
+
+	for index, newWeight := range weights {
+		if slot, ok := app.Slots[index]; ok {
 			slot.SetWeight(newWeight)
 		}
 	}
 
-	return app.TransitTo(APP_STATE_UPDATING, instances)
+	return nil
 }
 
 func (app *App) CancelUpdate() error {
+	if blueGreen, ok := app.StateMachine.CurrentState().(*StateBlueGreen); ok {
+		return blueGreen.Cancel()
+	}
+
 	if !app.StateMachine.CanTransitTo(APP_STATE_CANCEL_UPDATE) || app.ProposedVersion == nil {
 		return fmt.Errorf("state machine can not transit from state: %s to state: %s",
 			app.StateMachine.ReadableState(), APP_STATE_CANCEL_UPDATE)
@@ -267,10 +551,18 @@ func (app *App) EmitAppEvent(stateString string) {
 		eventType = eventbus.EventTypeAppStateUpdating
 	case APP_STATE_CANCEL_UPDATE:
 		eventType = eventbus.EventTypeAppStateCancelUpdate
+	case APP_STATE_CANARY:
+		eventType = eventbus.EventTypeAppStateCanary
+	case APP_STATE_BLUE_GREEN:
+		eventType = eventbus.EventTypeAppStateBlueGreen
 	case APP_STATE_SCALE_UP:
 		eventType = eventbus.EventTypeAppStateScaleUp
 	case APP_STATE_SCALE_DOWN:
 		eventType = eventbus.EventTypeAppStateScaleDown
+	case APP_STATE_SUSPENDED:
+		eventType = eventbus.EventTypeAppStateSuspended
+	case APP_STATE_RESTARTING:
+		eventType = eventbus.EventTypeAppStateRestarting
 	default:
 	}
 
@@ -325,16 +617,36 @@ func (app *App) stateFactory(stateName string, args ...interface{}) State {
 	case APP_STATE_SCALE_UP:
 		return NewStateScaleUp(app)
 	case APP_STATE_SCALE_DOWN:
-		return NewStateScaleDown(app)
+		var indices []int
+		if len(args) > 0 {
+			indices, _ = args[0].([]int)
+		}
+		return NewStateScaleDown(app, indices)
 	case APP_STATE_UPDATING:
 		slotCountNeedUpdate, ok := args[0].(int)
 		if !ok {
 			slotCountNeedUpdate = 1
 		}
-		return NewStateUpdating(app, slotCountNeedUpdate)
+
+		// failovers carried over from previous step of an automatic update
+		failovers := 0
+		if len(args) > 1 {
+			failovers, _ = args[1].(int)
+		}
+		return NewStateUpdating(app, slotCountNeedUpdate, failovers)
 
 	case APP_STATE_CANCEL_UPDATE:
 		return NewStateCancelUpdate(app)
+	case APP_STATE_CANARY:
+		return NewStateCanary(app)
+	case APP_STATE_BLUE_GREEN:
+		return NewStateBlueGreen(app)
+	case APP_STATE_SUSPENDED:
+		return NewStateSuspended(app)
+	case APP_STATE_RESTARTING:
+		from, _ := args[0].(int)
+		to, _ := args[1].(int)
+		return NewStateRestarting(app, from, to)
 	default:
 		panic(errors.New("unrecognized state"))
 	}
@@ -357,6 +669,44 @@ func (app *App) GetSlot(index int) (*Slot, bool) {
 	return slot, ok
 }
 
+// find slot running the task, slots may be reindexed after the task launched
+func (app *App) GetSlotByTaskID(taskID string) (*Slot, bool) {
+	for _, slot := range app.GetSlots() {
+		if slot.CurrentTask != nil && slot.CurrentTask.ID == taskID {
+			return slot, true
+		}
+	}
+
+	return nil, false
+}
+
+// move slots to new indices at once, tasks keep running with their ids
+func (app *App) reindexSlots(indices map[*Slot]int) {
+	ids := make(map[string]string)
+
+	app.slotsLock.Lock()
+	for slot := range indices {
+		delete(app.Slots, slot.Index)
+		slot.remove()
+	}
+
+	for slot, index := range indices {
+		oldID := slot.ID
+
+		slot.Index = index
+		slot.ID = fmt.Sprintf("%d-%s", index, app.ID)
+		app.Slots[index] = slot
+		slot.createWithHistory()
+
+		ids[oldID] = slot.ID
+	}
+	app.slotsLock.Unlock()
+
+	OfferAllocatorInstance().ReindexOfferSlotMap(ids)
+
+	app.Touch()
+}
+
 func (app *App) SetSlot(index int, slot *Slot) {
 	app.slotsLock.Lock()
 	app.Slots[index] = slot
@@ -382,10 +732,6 @@ func (app *App) checkProposedVersionValid(version *types.Version) error {
 		return fmt.Errorf("runAs can not change when update app, current version is %s", app.CurrentVersion.RunAs)
 	}
 
-	// appVersion should not equal
-	if version.AppVersion == app.CurrentVersion.AppVersion {
-		return fmt.Errorf("app version %s exists, choose another one", version.AppVersion)
-	}
 	// app instances should same as current instances
 	if version.Instances != app.CurrentVersion.Instances {
 		return fmt.Errorf("instances can not change when update app, current version is %d", app.CurrentVersion.Instances)
@@ -403,6 +749,12 @@ func (app *App) checkProposedVersionValid(version *types.Version) error {
 	return nil
 }
 
+// check a version without creating or updating any app, the version is left untouched
+func ValidateVersion(version *types.Version) error {
+	v := *version
+	return validateAndFormatVersion(&v)
+}
+
 func validateAndFormatVersion(version *types.Version) error {
 	if version.Container == nil {
 		return errors.New("swan only support mesos docker containerization, no container found")
@@ -476,15 +828,21 @@ func validateAndFormatVersion(version *types.Version) error {
 			}
 		}
 
+		// no port mappings on user defined network, the container port is checked
 		if version.HealthCheck != nil {
-			protocol := version.HealthCheck.Protocol
-			if !utils.SliceContains([]string{"cmd", "CMD"}, protocol) {
-				return fmt.Errorf("doesn't recoginized protocol %s for health check for fixed type app", protocol)
+			protocol := strings.ToLower(version.HealthCheck.Protocol)
+			if protocol != "cmd" && (version.HealthCheck.Port <= 0 || version.HealthCheck.Port > 65535) {
+				return fmt.Errorf("port in healthCheck section should be provided with %s protocol for fixed type app", protocol)
 			}
+		}
 
-			if len(version.HealthCheck.Value) == 0 {
-				return fmt.Errorf("no value provided for health check with %s", protocol)
-			}
+		// readiness check is probed through port mappings
+		if version.ReadinessCheck != nil {
+			return errors.New("fixed mode application doesn't support readiness check")
+		}
+
+		if version.PreStop != nil && version.PreStop.Path != "" {
+			return errors.New("fixed mode application doesn't support preStop hook, drainSeconds only")
 		}
 	} else {
 		// the only network driver should be **bridge**
@@ -526,25 +884,136 @@ func validateAndFormatVersion(version *types.Version) error {
 			if strings.ToLower(protocol) != "cmd" && !utils.SliceContains(portNames, portName) {
 				return fmt.Errorf("portname in healthCheck section should match that defined in portMappings")
 			}
+		}
 
-			if !utils.SliceContains([]string{"tcp", "http", "TCP", "HTTP", "cmd", "CMD"}, protocol) {
-				return fmt.Errorf("doesn't recoginized protocol %s for health check", protocol)
+		if check := version.ReadinessCheck; check != nil {
+			protocol := strings.ToLower(check.Protocol)
+			if protocol != "http" && protocol != "tcp" {
+				return fmt.Errorf("doesn't recoginized protocol %s for readiness check", check.Protocol)
 			}
 
-			if strings.ToLower(protocol) == "http" {
-				if len(version.HealthCheck.Path) == 0 {
-					return fmt.Errorf("no path provided for health check with %s protocol", protocol)
-				}
+			if !utils.SliceContains(portNames, check.PortName) {
+				return fmt.Errorf("portname in readinessCheck section should match that defined in portMappings")
+			}
+
+			if protocol == "http" && len(check.Path) == 0 {
+				return fmt.Errorf("no path provided for readiness check with %s protocol", check.Protocol)
+			}
+
+			if check.IntervalSeconds < 0 || check.TimeoutSeconds < 0 || check.DelaySeconds < 0 {
+				return errors.New("intervalSeconds, timeoutSeconds and delaySeconds of readiness check should not be negative")
+			}
+		}
+
+		if preStop := version.PreStop; preStop != nil && preStop.Path != "" {
+			if !utils.SliceContains(portNames, preStop.PortName) {
+				return fmt.Errorf("portname in preStop section should match that defined in portMappings")
+			}
+		}
+	}
+
+	if hc := version.HealthCheck; hc != nil {
+		protocol := strings.ToLower(hc.Protocol)
+		if !utils.SliceContains([]string{"cmd", "tcp", "http", "https", "grpc"}, protocol) {
+			return fmt.Errorf("doesn't recoginized protocol %s for health check", hc.Protocol)
+		}
+
+		if (protocol == "http" || protocol == "https") && len(hc.Path) == 0 {
+			return fmt.Errorf("no path provided for health check with %s protocol", hc.Protocol)
+		}
+
+		if protocol == "cmd" && len(hc.Value) == 0 {
+			return fmt.Errorf("no value provided for health check with %s protocol", hc.Protocol)
+		}
+
+		if (len(hc.Statuses) > 0 || len(hc.Headers) > 0) && protocol != "http" && protocol != "https" {
+			return fmt.Errorf("statuses and headers are not supported for health check with %s protocol", hc.Protocol)
+		}
+
+		for _, status := range hc.Statuses {
+			if status < 100 || status > 599 {
+				return fmt.Errorf("invalid status %d for health check", status)
+			}
+		}
+
+		if hc.KillAfterUnhealthySeconds < 0 {
+			return errors.New("killAfterUnhealthySeconds of health check should not be negative")
+		}
+	}
+
+	if preStop := version.PreStop; preStop != nil {
+		if preStop.DrainSeconds < 0 || preStop.TimeoutSeconds < 0 {
+			return errors.New("drainSeconds and timeoutSeconds of preStop should not be negative")
+		}
+	}
+
+	// validate update policy
+	if policy := version.UpdatePolicy; policy != nil {
+		if policy.BatchSize < 0 || policy.UpdateDelay < 0 || policy.MaxFailovers < 0 {
+			return errors.New("batchSize, updateDelay and maxFailovers of update policy should not be negative")
+		}
+
+		if policy.MaxSurge < 0 || policy.MaxUnavailable < 0 {
+			return errors.New("maxSurge and maxUnavailable of update policy should not be negative")
+		}
+
+		// surge slots can not hold the same ips as the old ones
+		if policy.MaxSurge > 0 && network != "host" && network != "bridge" {
+			return errors.New("maxSurge doesn't support fixed mode application")
+		}
+
+		if policy.Action != "" &&
+			!utils.SliceContains([]string{UPDATE_POLICY_ACTION_ROLLBACK, UPDATE_POLICY_ACTION_PAUSE}, policy.Action) {
+			return fmt.Errorf("doesn't recoginized update policy action %s", policy.Action)
+		}
+
+		if policy.Strategy != "" &&
+			!utils.SliceContains([]string{UPDATE_STRATEGY_ROLLING, UPDATE_STRATEGY_CANARY, UPDATE_STRATEGY_BLUE_GREEN}, policy.Strategy) {
+			return fmt.Errorf("doesn't recoginized update strategy %s", policy.Strategy)
+		}
+
+		// old and new slots of fixed mode app can not hold the same ips at the same time
+		if policy.Strategy == UPDATE_STRATEGY_BLUE_GREEN && network != "host" && network != "bridge" {
+			return errors.New("blueGreen update strategy doesn't support fixed mode application")
+		}
+
+		if policy.Strategy == UPDATE_STRATEGY_CANARY {
+			if canaryInstances(version) >= int(version.Instances) {
+				return errors.New("canary instances should be less than instances of app")
 			}
 
-			if strings.ToLower(protocol) == "cmd" {
-				if len(version.HealthCheck.Value) == 0 {
-					return fmt.Errorf("no value provided for health check with %s protocol", protocol)
+			if canary := policy.Canary; canary != nil {
+				if canary.Instances < 0 || canary.StepInterval < 0 {
+					return errors.New("instances and stepInterval of canary should not be negative")
+				}
+
+				if canary.MaxErrorRate < 0 || canary.MaxErrorRate > 1 {
+					return errors.New("maxErrorRate of canary should between 0 and 1")
+				}
+
+				for i, step := range canary.WeightSteps {
+					if step <= 0 || step > 100 || (i > 0 && step <= canary.WeightSteps[i-1]) {
+						return errors.New("weightSteps of canary should be increasing percentages between 0 and 100")
+					}
 				}
 			}
 		}
 	}
 
+	// validate restart policy
+	if version.BackoffSeconds < 0 || version.MaxLaunchDelaySeconds < 0 || version.MaxRestarts < 0 {
+		return errors.New("backoffSeconds, maxLaunchDelaySeconds and maxRestarts should not be negative")
+	}
+
+	if version.BackoffFactor != 0 && version.BackoffFactor < 1 {
+		return errors.New("backoffFactor should not be less than 1")
+	}
+
+	if version.RestartPolicy != "" &&
+		!utils.SliceContains([]string{RESTART_POLICY_ALWAYS, RESTART_POLICY_ON_FAILURE, RESTART_POLICY_NEVER}, version.RestartPolicy) {
+		return fmt.Errorf("doesn't recoginized restart policy %s", version.RestartPolicy)
+	}
+
 	// validate constraints are all valid
 	if len(version.Constraints) > 0 {
 		evalStatement, err := ParseConstraint(strings.ToLower(version.Constraints))
@@ -561,6 +1030,16 @@ func validateAndFormatVersion(version *types.Version) error {
 	return nil
 }
 
+func (app *App) LookupVersion(versionID string) *types.Version {
+	for _, v := range app.Versions {
+		if v.ID == versionID {
+			return v
+		}
+	}
+
+	return nil
+}
+
 func (app *App) SaveVersion(version *types.Version) {
 	app.Versions = append(app.Versions, version)
 	store.DB().CreateVersion(app.ID, VersionToRaft(version, app.ID))
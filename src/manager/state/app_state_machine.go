@@ -11,8 +11,12 @@ const (
 	APP_STATE_DELETING      = "deleting"
 	APP_STATE_UPDATING      = "updating"
 	APP_STATE_CANCEL_UPDATE = "cancel_update"
+	APP_STATE_CANARY        = "canary"
+	APP_STATE_BLUE_GREEN    = "blue_green"
 	APP_STATE_SCALE_UP      = "scale_up"
 	APP_STATE_SCALE_DOWN    = "scale_down"
+	APP_STATE_SUSPENDED     = "suspended"
+	APP_STATE_RESTARTING    = "restarting"
 )
 
 type StateMachine struct {
@@ -15,6 +15,7 @@ func AppToRaft(app *App) *store.Application {
 		ClusterID: app.ClusterID,
 		CreatedAt: app.Created.UnixNano(),
 		UpdatedAt: app.Updated.UnixNano(),
+		Job:       app.Job,
 	}
 
 	if app.CurrentVersion != nil {
@@ -29,9 +30,31 @@ func AppToRaft(app *App) *store.Application {
 		raftApp.StateMachine = StateMachineToRaft(app.StateMachine)
 	}
 
+	for _, rollback := range app.Rollbacks {
+		raftApp.Rollbacks = append(raftApp.Rollbacks, RollbackToRaft(rollback))
+	}
+
 	return raftApp
 }
 
+func RollbackToRaft(rollback *types.Rollback) *store.Rollback {
+	return &store.Rollback{
+		ID:            rollback.ID,
+		FromVersionID: rollback.FromVersionID,
+		ToVersionID:   rollback.ToVersionID,
+		CreatedAt:     rollback.Created.UnixNano(),
+	}
+}
+
+func RollbackFromRaft(raftRollback *store.Rollback) *types.Rollback {
+	return &types.Rollback{
+		ID:            raftRollback.ID,
+		FromVersionID: raftRollback.FromVersionID,
+		ToVersionID:   raftRollback.ToVersionID,
+		Created:       time.Unix(0, raftRollback.CreatedAt),
+	}
+}
+
 func VersionToRaft(version *types.Version, appID string) *store.Version {
 	raftVersion := &store.Version{
 		ID:          version.ID,
@@ -51,6 +74,14 @@ func VersionToRaft(version *types.Version, appID string) *store.Version {
 		AppName:     version.AppName,
 		AppID:       appID,
 		AppVersion:  version.AppVersion,
+
+		BackoffSeconds:        version.BackoffSeconds,
+		BackoffFactor:         version.BackoffFactor,
+		MaxLaunchDelaySeconds: version.MaxLaunchDelaySeconds,
+		MaxRestarts:           version.MaxRestarts,
+		RestartPolicy:         version.RestartPolicy,
+
+		NonPreemptible: version.NonPreemptible,
 	}
 
 	if version.Container != nil {
@@ -69,6 +100,14 @@ func VersionToRaft(version *types.Version, appID string) *store.Version {
 		raftVersion.HealthCheck = HealthCheckToRaft(version.HealthCheck)
 	}
 
+	if version.ReadinessCheck != nil {
+		raftVersion.ReadinessCheck = ReadinessCheckToRaft(version.ReadinessCheck)
+	}
+
+	if version.PreStop != nil {
+		raftVersion.PreStop = PreStopToRaft(version.PreStop)
+	}
+
 	if version.Gateway != nil {
 		raftVersion.Gateway = GatewayToRaft(version.Gateway)
 	}
@@ -94,6 +133,14 @@ func VersionFromRaft(raftVersion *store.Version) *types.Version {
 		URIs:        raftVersion.Uris,
 		IP:          raftVersion.Ip,
 		AppVersion:  raftVersion.AppVersion,
+
+		BackoffSeconds:        raftVersion.BackoffSeconds,
+		BackoffFactor:         raftVersion.BackoffFactor,
+		MaxLaunchDelaySeconds: raftVersion.MaxLaunchDelaySeconds,
+		MaxRestarts:           raftVersion.MaxRestarts,
+		RestartPolicy:         raftVersion.RestartPolicy,
+
+		NonPreemptible: raftVersion.NonPreemptible,
 	}
 
 	if raftVersion.Container != nil {
@@ -112,6 +159,14 @@ func VersionFromRaft(raftVersion *store.Version) *types.Version {
 		version.HealthCheck = HealthCheckFromRaft(raftVersion.HealthCheck)
 	}
 
+	if raftVersion.ReadinessCheck != nil {
+		version.ReadinessCheck = ReadinessCheckFromRaft(raftVersion.ReadinessCheck)
+	}
+
+	if raftVersion.PreStop != nil {
+		version.PreStop = PreStopFromRaft(raftVersion.PreStop)
+	}
+
 	if raftVersion.Gateway != nil {
 		version.Gateway = GatewayFromRaft(raftVersion.Gateway)
 	}
@@ -283,21 +338,49 @@ func KillPolicyFromRaft(raftKillPolicy *store.KillPolicy) *types.KillPolicy {
 }
 
 func UpdatePolicyToRaft(updatePolicy *types.UpdatePolicy) *store.UpdatePolicy {
-	return &store.UpdatePolicy{
-		UpdateDelay:  updatePolicy.UpdateDelay,
-		MaxRetries:   updatePolicy.MaxRetries,
-		MaxFailovers: updatePolicy.MaxFailovers,
-		Action:       updatePolicy.Action,
+	raftUpdatePolicy := &store.UpdatePolicy{
+		BatchSize:      updatePolicy.BatchSize,
+		UpdateDelay:    updatePolicy.UpdateDelay,
+		MaxFailovers:   updatePolicy.MaxFailovers,
+		Action:         updatePolicy.Action,
+		MaxSurge:       updatePolicy.MaxSurge,
+		MaxUnavailable: updatePolicy.MaxUnavailable,
+		Strategy:       updatePolicy.Strategy,
+	}
+
+	if updatePolicy.Canary != nil {
+		raftUpdatePolicy.Canary = &store.CanaryPolicy{
+			Instances:    updatePolicy.Canary.Instances,
+			WeightSteps:  updatePolicy.Canary.WeightSteps,
+			StepInterval: updatePolicy.Canary.StepInterval,
+			MaxErrorRate: updatePolicy.Canary.MaxErrorRate,
+		}
 	}
+
+	return raftUpdatePolicy
 }
 
 func UpdatePolicyFromRaft(raftUpdatePolicy *store.UpdatePolicy) *types.UpdatePolicy {
-	return &types.UpdatePolicy{
-		UpdateDelay:  raftUpdatePolicy.UpdateDelay,
-		MaxRetries:   raftUpdatePolicy.MaxRetries,
-		MaxFailovers: raftUpdatePolicy.MaxFailovers,
-		Action:       raftUpdatePolicy.Action,
+	updatePolicy := &types.UpdatePolicy{
+		BatchSize:      raftUpdatePolicy.BatchSize,
+		UpdateDelay:    raftUpdatePolicy.UpdateDelay,
+		MaxFailovers:   raftUpdatePolicy.MaxFailovers,
+		Action:         raftUpdatePolicy.Action,
+		MaxSurge:       raftUpdatePolicy.MaxSurge,
+		MaxUnavailable: raftUpdatePolicy.MaxUnavailable,
+		Strategy:       raftUpdatePolicy.Strategy,
+	}
+
+	if raftUpdatePolicy.Canary != nil {
+		updatePolicy.Canary = &types.CanaryPolicy{
+			Instances:    raftUpdatePolicy.Canary.Instances,
+			WeightSteps:  raftUpdatePolicy.Canary.WeightSteps,
+			StepInterval: raftUpdatePolicy.Canary.StepInterval,
+			MaxErrorRate: raftUpdatePolicy.Canary.MaxErrorRate,
+		}
 	}
+
+	return updatePolicy
 }
 
 func HealthCheckToRaft(healthCheck *types.HealthCheck) *store.HealthCheck {
@@ -306,6 +389,7 @@ func HealthCheckToRaft(healthCheck *types.HealthCheck) *store.HealthCheck {
 		Address:             healthCheck.Address,
 		Protocol:            healthCheck.Protocol,
 		PortName:            healthCheck.PortName,
+		Port:                healthCheck.Port,
 		Path:                healthCheck.Path,
 		Value:               healthCheck.Value,
 		ConsecutiveFailures: healthCheck.ConsecutiveFailures,
@@ -313,6 +397,12 @@ func HealthCheckToRaft(healthCheck *types.HealthCheck) *store.HealthCheck {
 		IntervalSeconds:     healthCheck.IntervalSeconds,
 		TimeoutSeconds:      healthCheck.TimeoutSeconds,
 		DelaySeconds:        healthCheck.DelaySeconds,
+
+		KillAfterUnhealthySeconds: healthCheck.KillAfterUnhealthySeconds,
+
+		Statuses: healthCheck.Statuses,
+		Headers:  healthCheck.Headers,
+		Service:  healthCheck.Service,
 	}
 
 	return raftHealthCheck
@@ -324,6 +414,7 @@ func HealthCheckFromRaft(raftHealthCheck *store.HealthCheck) *types.HealthCheck
 		Address:             raftHealthCheck.Address,
 		Protocol:            raftHealthCheck.Protocol,
 		PortName:            raftHealthCheck.PortName,
+		Port:                raftHealthCheck.Port,
 		Path:                raftHealthCheck.Path,
 		Value:               raftHealthCheck.Value,
 		ConsecutiveFailures: raftHealthCheck.ConsecutiveFailures,
@@ -331,11 +422,57 @@ func HealthCheckFromRaft(raftHealthCheck *store.HealthCheck) *types.HealthCheck
 		IntervalSeconds:     raftHealthCheck.IntervalSeconds,
 		TimeoutSeconds:      raftHealthCheck.TimeoutSeconds,
 		DelaySeconds:        raftHealthCheck.DelaySeconds,
+
+		KillAfterUnhealthySeconds: raftHealthCheck.KillAfterUnhealthySeconds,
+
+		Statuses: raftHealthCheck.Statuses,
+		Headers:  raftHealthCheck.Headers,
+		Service:  raftHealthCheck.Service,
 	}
 
 	return healthCheck
 }
 
+func ReadinessCheckToRaft(readinessCheck *types.ReadinessCheck) *store.ReadinessCheck {
+	return &store.ReadinessCheck{
+		Protocol:        readinessCheck.Protocol,
+		PortName:        readinessCheck.PortName,
+		Path:            readinessCheck.Path,
+		IntervalSeconds: readinessCheck.IntervalSeconds,
+		TimeoutSeconds:  readinessCheck.TimeoutSeconds,
+		DelaySeconds:    readinessCheck.DelaySeconds,
+	}
+}
+
+func ReadinessCheckFromRaft(raftReadinessCheck *store.ReadinessCheck) *types.ReadinessCheck {
+	return &types.ReadinessCheck{
+		Protocol:        raftReadinessCheck.Protocol,
+		PortName:        raftReadinessCheck.PortName,
+		Path:            raftReadinessCheck.Path,
+		IntervalSeconds: raftReadinessCheck.IntervalSeconds,
+		TimeoutSeconds:  raftReadinessCheck.TimeoutSeconds,
+		DelaySeconds:    raftReadinessCheck.DelaySeconds,
+	}
+}
+
+func PreStopToRaft(preStop *types.PreStop) *store.PreStop {
+	return &store.PreStop{
+		PortName:       preStop.PortName,
+		Path:           preStop.Path,
+		TimeoutSeconds: preStop.TimeoutSeconds,
+		DrainSeconds:   preStop.DrainSeconds,
+	}
+}
+
+func PreStopFromRaft(raftPreStop *store.PreStop) *types.PreStop {
+	return &types.PreStop{
+		PortName:       raftPreStop.PortName,
+		Path:           raftPreStop.Path,
+		TimeoutSeconds: raftPreStop.TimeoutSeconds,
+		DrainSeconds:   raftPreStop.DrainSeconds,
+	}
+}
+
 func GatewayToRaft(gateway *types.Gateway) *store.Gateway {
 	raftGateway := &store.Gateway{
 		Weight:  gateway.Weight,
@@ -360,20 +497,19 @@ func SlotToRaft(slot *Slot) *store.Slot {
 		ID:        slot.ID,
 		AppID:     slot.App.ID,
 		VersionID: slot.Version.ID,
-		Healthy:   slot.Healthy(),
+		Healthy:   slot.healthy,
+		Ready:     slot.ready,
 		State:     slot.State,
 		Weight:    slot.GetWeight(),
+		Standby:   slot.Standby(),
 	}
 
-	if slot.CurrentTask != nil {
-		raftSlot.CurrentTask = TaskToRaft(slot.CurrentTask)
+	if health := slot.Health(); health != nil {
+		raftSlot.Health = TaskHealthToRaft(health)
 	}
 
-	if len(slot.TaskHistory) > 0 {
-		raftSlot.TaskHistory = make([]*store.Task, 0)
-		for _, t := range slot.TaskHistory {
-			raftSlot.TaskHistory = append(raftSlot.TaskHistory, TaskToRaft(t))
-		}
+	if slot.CurrentTask != nil {
+		raftSlot.CurrentTask = TaskToRaft(slot.CurrentTask)
 	}
 
 	return raftSlot
@@ -389,10 +525,16 @@ func SlotFromRaft(raftSlot *store.Slot, app *App) *Slot {
 		Ip:            raftSlot.CurrentTask.Ip,
 		AgentHostName: raftSlot.CurrentTask.AgentHostName,
 		healthy:       raftSlot.Healthy,
+		ready:         raftSlot.Ready,
 		weight:        raftSlot.Weight,
+		standby:       raftSlot.Standby,
 		TaskHistory:   make([]*Task, 0),
 	}
 
+	if raftSlot.Health != nil {
+		slot.health = TaskHealthFromRaft(raftSlot.Health)
+	}
+
 	if raftSlot.CurrentTask != nil {
 		slot.CurrentTask = TaskFromRaft(raftSlot.CurrentTask, app)
 		slot.CurrentTask.Slot = slot
@@ -413,6 +555,59 @@ func SlotFromRaft(raftSlot *store.Slot, app *App) *Slot {
 	return slot
 }
 
+func TaskHealthToRaft(health *types.TaskHealth) *store.TaskHealth {
+	raftHealth := &store.TaskHealth{
+		Healthy:             health.Healthy,
+		ConsecutiveFailures: int32(health.ConsecutiveFailures),
+	}
+
+	if health.LastCheck != nil {
+		raftHealth.LastCheckAt = health.LastCheck.UnixNano()
+	}
+
+	if health.LastTransition != nil {
+		raftHealth.LastTransitionAt = health.LastTransition.UnixNano()
+	}
+
+	for _, outcome := range health.Outcomes {
+		raftHealth.Outcomes = append(raftHealth.Outcomes, &store.HealthCheckOutcome{
+			At:      outcome.Time.UnixNano(),
+			Healthy: outcome.Healthy,
+			Message: outcome.Message,
+		})
+	}
+
+	return raftHealth
+}
+
+func TaskHealthFromRaft(raftHealth *store.TaskHealth) *types.TaskHealth {
+	health := &types.TaskHealth{
+		Healthy:             raftHealth.Healthy,
+		ConsecutiveFailures: int(raftHealth.ConsecutiveFailures),
+		Outcomes:            make([]*types.HealthCheckOutcome, 0),
+	}
+
+	if raftHealth.LastCheckAt > 0 {
+		lastCheck := time.Unix(0, raftHealth.LastCheckAt)
+		health.LastCheck = &lastCheck
+	}
+
+	if raftHealth.LastTransitionAt > 0 {
+		lastTransition := time.Unix(0, raftHealth.LastTransitionAt)
+		health.LastTransition = &lastTransition
+	}
+
+	for _, outcome := range raftHealth.Outcomes {
+		health.Outcomes = append(health.Outcomes, &types.HealthCheckOutcome{
+			Time:    time.Unix(0, outcome.At),
+			Healthy: outcome.Healthy,
+			Message: outcome.Message,
+		})
+	}
+
+	return health
+}
+
 func TaskToRaft(task *Task) *store.Task {
 	return &store.Task{
 		ID:            task.ID,
@@ -429,6 +624,7 @@ func TaskToRaft(task *Task) *store.Task {
 		AgentHostName: task.AgentHostName,
 		Reason:        task.Reason,
 		Message:       task.Message,
+		Source:        task.Source,
 		CreatedAt:     task.Created.UnixNano(),
 		ArchivedAt:    task.ArchivedAt.UnixNano(),
 		ContainerId:   task.ContainerId,
@@ -449,11 +645,16 @@ func TaskFromRaft(raftTask *store.Task, app *App) *Task {
 		AgentHostName: raftTask.AgentHostName,
 		Reason:        raftTask.Reason,
 		Message:       raftTask.Message,
+		Source:        raftTask.Source,
 		Created:       time.Unix(0, raftTask.CreatedAt),
 		ContainerId:   raftTask.ContainerId,
 		ContainerName: raftTask.ContainerName,
 	}
 
+	if raftTask.ArchivedAt > 0 {
+		task.ArchivedAt = time.Unix(0, raftTask.ArchivedAt)
+	}
+
 	for _, version := range app.Versions {
 		if raftTask.VersionID == version.ID {
 			task.Version = version
@@ -513,12 +714,18 @@ func StateFromRaft(app *App, state *store.State) State {
 			TargetSlotIndex:  int(state.TargetSlotIndex),
 		}
 	case APP_STATE_SCALE_DOWN:
+		indices := make([]int, 0)
+		for _, index := range state.Indices {
+			indices = append(indices, int(index))
+		}
+
 		return &StateScaleDown{
 			App:              app,
 			Name:             APP_STATE_SCALE_DOWN,
 			CurrentSlotIndex: int(state.CurrentSlotIndex),
 			CurrentSlot:      slot,
 			TargetSlotIndex:  int(state.TargetSlotIndex),
+			Indices:          indices,
 		}
 	case APP_STATE_CANCEL_UPDATE:
 		return &StateCancelUpdate{
@@ -536,6 +743,39 @@ func StateFromRaft(app *App, state *store.State) State {
 			CurrentSlot:         slot,
 			TargetSlotIndex:     int(state.TargetSlotIndex),
 			SlotCountNeedUpdate: int(state.SlotCountNeedUpdate),
+			Failovers:           int(state.Failovers),
+			Paused:              state.Paused,
+			Offset:              int(state.Offset),
+		}
+
+	case APP_STATE_CANARY:
+		return &StateCanary{
+			App:        app,
+			Name:       APP_STATE_CANARY,
+			CanaryStep: int(state.CanaryStep),
+		}
+
+	case APP_STATE_BLUE_GREEN:
+		return &StateBlueGreen{
+			App:     app,
+			Name:    APP_STATE_BLUE_GREEN,
+			Offset:  int(state.Offset),
+			Flipped: state.Flipped,
+		}
+
+	case APP_STATE_RESTARTING:
+		return &StateRestarting{
+			App:              app,
+			Name:             APP_STATE_RESTARTING,
+			CurrentSlotIndex: int(state.CurrentSlotIndex),
+			CurrentSlot:      slot,
+			TargetSlotIndex:  int(state.TargetSlotIndex),
+		}
+
+	case APP_STATE_SUSPENDED:
+		return &StateSuspended{
+			App:  app,
+			Name: APP_STATE_SUSPENDED,
 		}
 
 	case APP_STATE_DELETING:
@@ -573,6 +813,20 @@ func StateToRaft(state State) *store.State {
 			raftState.TargetSlotIndex = int64(f.Interface().(int))
 		case "SlotCountNeedUpdate":
 			raftState.SlotCountNeedUpdate = int64(f.Interface().(int))
+		case "Failovers":
+			raftState.Failovers = int64(f.Interface().(int))
+		case "Paused":
+			raftState.Paused = f.Interface().(bool)
+		case "CanaryStep":
+			raftState.CanaryStep = int64(f.Interface().(int))
+		case "Offset":
+			raftState.Offset = int64(f.Interface().(int))
+		case "Flipped":
+			raftState.Flipped = f.Interface().(bool)
+		case "Indices":
+			for _, index := range f.Interface().([]int) {
+				raftState.Indices = append(raftState.Indices, int64(index))
+			}
 		default:
 		}
 	}
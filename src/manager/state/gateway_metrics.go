@@ -0,0 +1,128 @@
+package state
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	GATEWAY_METRICS_PATH           = "/gateway-metrics"
+	GATEWAY_REQUESTS_METRIC_SUFFIX = "_requests_total"
+)
+
+var (
+	gatewayMetricsAddrs     []string
+	gatewayMetricsAddrsLock sync.Mutex
+
+	gatewayMetricsClient = &http.Client{Timeout: 5 * time.Second}
+)
+
+// requests counted by gateways of all agents for a group of tasks
+type GatewayRequestStats struct {
+	Total  float64
+	Errors float64
+}
+
+// error ratio of requests made between two samples
+func (stats *GatewayRequestStats) ErrorRateSince(prev *GatewayRequestStats) float64 {
+	total := stats.Total
+	errs := stats.Errors
+	if prev != nil {
+		total -= prev.Total
+		errs -= prev.Errors
+	}
+
+	if total <= 0 {
+		return 0
+	}
+
+	return errs / total
+}
+
+// agent http addresses where gateway metrics can be scraped
+func SetGatewayMetricsAddrs(addrs []string) {
+	gatewayMetricsAddrsLock.Lock()
+	defer gatewayMetricsAddrsLock.Unlock()
+
+	gatewayMetricsAddrs = addrs
+}
+
+func GatewayMetricsAddrs() []string {
+	gatewayMetricsAddrsLock.Lock()
+	defer gatewayMetricsAddrsLock.Unlock()
+
+	return gatewayMetricsAddrs
+}
+
+// sum up requests and 5xx responses of specified tasks from all gateways
+func FetchGatewayRequestStats(taskIDs []string) (*GatewayRequestStats, error) {
+	ids := make(map[string]bool)
+	for _, id := range taskIDs {
+		ids[strings.ToLower(id)] = true
+	}
+
+	stats := &GatewayRequestStats{}
+	for _, addr := range GatewayMetricsAddrs() {
+		if err := scrapeGatewayRequests(addr, ids, stats); err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+func scrapeGatewayRequests(addr string, taskIDs map[string]bool, stats *GatewayRequestStats) error {
+	resp, err := gatewayMetricsClient.Get(fmt.Sprintf("http://%s%s", addr, GATEWAY_METRICS_PATH))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("scrape gateway metrics from %s got status %d", addr, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	for name, family := range families {
+		if !strings.HasSuffix(name, GATEWAY_REQUESTS_METRIC_SUFFIX) {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			var taskID, code string
+			for _, label := range metric.GetLabel() {
+				switch label.GetName() {
+				case "taskId":
+					taskID = label.GetValue()
+				case "code":
+					code = label.GetValue()
+				}
+			}
+
+			if !taskIDs[strings.ToLower(taskID)] || metric.GetCounter() == nil {
+				continue
+			}
+
+			value := metric.GetCounter().GetValue()
+			stats.Total += value
+			if strings.HasPrefix(code, "5") {
+				stats.Errors += value
+			}
+		}
+	}
+
+	logrus.Debugf("gateway requests of tasks %v after scraping %s: %+v", taskIDs, addr, stats)
+
+	return nil
+}
@@ -1,37 +1,69 @@
 package state
 
 import (
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+)
+
+const (
+	RESTART_POLICY_ALWAYS     = "always"
+	RESTART_POLICY_ON_FAILURE = "onFailure"
+	RESTART_POLICY_NEVER      = "never"
+
+	DEFAULT_BACKOFF_SECONDS          = 10
+	DEFAULT_BACKOFF_FACTOR           = 1
+	DEFAULT_MAX_LAUNCH_DELAY_SECONDS = 300
 )
 
+// return true if the slot got relaunched
 type TestAndRestartFunc func(slot *Slot) bool
 
 type RestartPolicy struct {
 	BackoffSeconds        time.Duration
 	BackoffFactor         float64
 	MaxLaunchDelaySeconds time.Duration
+	MaxRestarts           int
 
+	restarts        int
 	currentInterval time.Duration
 	checkTimer      *time.Timer
 	restartFunc     TestAndRestartFunc
+	stopped         bool
+	lock            sync.Mutex
 
 	slot *Slot
 }
 
-func NewRestartPolicy(slot *Slot, BackoffSeconds time.Duration, BackoffFactor float64,
-	MaxLaunchDelaySeconds time.Duration, restartFunc TestAndRestartFunc) *RestartPolicy {
+func NewRestartPolicy(slot *Slot, version *types.Version, restartFunc TestAndRestartFunc) *RestartPolicy {
+	backoff, factor, maxDelay := version.BackoffSeconds, version.BackoffFactor, version.MaxLaunchDelaySeconds
+	if backoff <= 0 {
+		backoff = DEFAULT_BACKOFF_SECONDS
+	}
+	if factor <= 0 {
+		factor = DEFAULT_BACKOFF_FACTOR
+	}
+	if maxDelay <= 0 {
+		maxDelay = DEFAULT_MAX_LAUNCH_DELAY_SECONDS
+	}
 
 	p := &RestartPolicy{
-		BackoffSeconds:        BackoffSeconds,
-		BackoffFactor:         BackoffFactor,
-		MaxLaunchDelaySeconds: MaxLaunchDelaySeconds,
-		currentInterval:       BackoffSeconds,
+		BackoffSeconds:        time.Duration(backoff * float64(time.Second)),
+		BackoffFactor:         factor,
+		MaxLaunchDelaySeconds: time.Duration(maxDelay * float64(time.Second)),
+		MaxRestarts:           int(version.MaxRestarts),
 		slot:                  slot,
 		restartFunc:           restartFunc,
 	}
 
+	p.currentInterval = p.BackoffSeconds
+	if p.currentInterval > p.MaxLaunchDelaySeconds {
+		p.currentInterval = p.MaxLaunchDelaySeconds
+	}
+
 	p.checkTimer = time.AfterFunc(p.currentInterval, func() {
 		p.restartAndSetNextTimer()
 	})
@@ -39,17 +71,58 @@ func NewRestartPolicy(slot *Slot, BackoffSeconds time.Duration, BackoffFactor fl
 	return p
 }
 
+// the slot is checked every currentInterval, which grows by BackoffFactor
+// after every relaunch until MaxLaunchDelaySeconds reached
 func (rs *RestartPolicy) restartAndSetNextTimer() {
 	logrus.Debugf("call restartFunc now for %s", rs.slot.ID)
 
-	stopCheck := rs.restartFunc(rs.slot)
-	rs.currentInterval = time.Duration(rs.currentInterval.Seconds()*rs.BackoffFactor) * time.Second
-	if !stopCheck && rs.currentInterval < rs.MaxLaunchDelaySeconds {
-		rs.checkTimer = time.AfterFunc(rs.currentInterval, rs.restartAndSetNextTimer)
+	rs.lock.Lock()
+	stopped := rs.stopped
+	rs.lock.Unlock()
+	if stopped {
+		return
 	}
+
+	// not locked, relaunching steps the app which may stop this policy
+	restarted := rs.restartFunc(rs.slot)
+
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	if rs.stopped {
+		return
+	}
+
+	if restarted {
+		rs.restarts += 1
+
+		rs.currentInterval = time.Duration(float64(rs.currentInterval) * rs.BackoffFactor)
+		if rs.currentInterval > rs.MaxLaunchDelaySeconds {
+			rs.currentInterval = rs.MaxLaunchDelaySeconds
+		}
+	}
+
+	if rs.MaxRestarts > 0 && rs.restarts >= rs.MaxRestarts {
+		logrus.Warnf("slot %s restarted %d times, no more restarts", rs.slot.ID, rs.restarts)
+		return
+	}
+
+	rs.checkTimer = time.AfterFunc(rs.currentInterval, rs.restartAndSetNextTimer)
+}
+
+// whether the slot would still be relaunched once its task gone
+func (rs *RestartPolicy) CanRestart() bool {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	return !rs.stopped && (rs.MaxRestarts <= 0 || rs.restarts < rs.MaxRestarts)
 }
 
 func (rs *RestartPolicy) Stop() {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rs.stopped = true
 	if rs.checkTimer != nil {
 		logrus.Infof("stop RestartPolicy timer for slot %s", rs.slot.ID)
 		rs.checkTimer.Stop()
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// recent health check outcomes kept for each slot
+	HEALTH_OUTCOMES_KEPT = 10
+)
+
+// record a health check result of the current task reported by mesos,
+// mesos reports the result only, not how long the check took
+func (slot *Slot) RecordHealthCheck(healthy bool, at time.Time, message string) {
+	slot.healthLock.Lock()
+	defer slot.healthLock.Unlock()
+
+	if slot.health == nil {
+		slot.health = &types.TaskHealth{
+			Outcomes: make([]*types.HealthCheckOutcome, 0),
+		}
+	}
+
+	health := slot.health
+	if health.LastCheck == nil || health.Healthy != healthy {
+		health.LastTransition = &at
+	}
+
+	health.Healthy = healthy
+	health.LastCheck = &at
+
+	if healthy {
+		health.ConsecutiveFailures = 0
+	} else {
+		health.ConsecutiveFailures += 1
+	}
+
+	health.Outcomes = append(health.Outcomes, &types.HealthCheckOutcome{
+		Time:    at,
+		Healthy: healthy,
+		Message: message,
+	})
+	if len(health.Outcomes) > HEALTH_OUTCOMES_KEPT {
+		health.Outcomes = health.Outcomes[len(health.Outcomes)-HEALTH_OUTCOMES_KEPT:]
+	}
+}
+
+// a copy of the health record, nil if no health check result reported yet
+func (slot *Slot) Health() *types.TaskHealth {
+	slot.healthLock.Lock()
+	defer slot.healthLock.Unlock()
+
+	if slot.health == nil {
+		return nil
+	}
+
+	health := *slot.health
+	health.Outcomes = make([]*types.HealthCheckOutcome, len(slot.health.Outcomes))
+	copy(health.Outcomes, slot.health.Outcomes)
+
+	return &health
+}
+
+// health record belongs to the current task, dropped once a new task dispatched
+func (slot *Slot) resetHealth() {
+	slot.healthLock.Lock()
+	defer slot.healthLock.Unlock()
+
+	slot.health = nil
+	slot.unhealthyKilled = false
+}
+
+// watch running slot reported unhealthy, kill its task once unhealthy longer
+// than killAfterUnhealthySeconds of health check, counted from the transition
+func (slot *Slot) watchUnhealthy() {
+	slot.healthLock.Lock()
+	defer slot.healthLock.Unlock()
+
+	hc := slot.Version.HealthCheck
+	if hc == nil || hc.KillAfterUnhealthySeconds <= 0 || slot.health == nil || slot.health.Healthy ||
+		!slot.StateIs(SLOT_STATE_TASK_RUNNING) {
+		if slot.unhealthyTimer != nil {
+			slot.unhealthyTimer.Stop()
+			slot.unhealthyTimer = nil
+		}
+		return
+	}
+
+	if slot.unhealthyTimer != nil || slot.unhealthyKilled {
+		return
+	}
+
+	threshold := time.Duration(hc.KillAfterUnhealthySeconds * float64(time.Second))
+	slot.unhealthyTimer = time.AfterFunc(threshold-time.Since(*slot.health.LastTransition), slot.killUnhealthy)
+}
+
+// task is killed only, the slot is relaunched by restart policy with backoff
+func (slot *Slot) killUnhealthy() {
+	slot.healthLock.Lock()
+	slot.unhealthyTimer = nil
+	unhealthy := slot.health != nil && !slot.health.Healthy
+	// nobody would relaunch the slot, eg. being killed by app state machine
+	// or out of restarts
+	restartPolicy := slot.restartPolicy
+	if !unhealthy || !slot.StateIs(SLOT_STATE_TASK_RUNNING) || restartPolicy == nil || !restartPolicy.CanRestart() {
+		slot.healthLock.Unlock()
+		return
+	}
+	slot.unhealthyKilled = true
+	slot.healthLock.Unlock()
+
+	reason := fmt.Sprintf("unhealthy for more than %v seconds", slot.Version.HealthCheck.KillAfterUnhealthySeconds)
+	logrus.Warnf("kill task of slot %s: %s", slot.ID, reason)
+
+	e := slot.BuildTaskEvent(eventbus.EventTypeTaskUnhealthyKilled)
+	e.Payload.(*types.TaskInfoEvent).Reason = reason
+	eventbus.WriteEvent(e)
+
+	slot.CurrentTask.Kill()
+}
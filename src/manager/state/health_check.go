@@ -0,0 +1,109 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
+	"github.com/Dataman-Cloud/swan/src/types"
+)
+
+// accepted by http(s) health check if statuses not specified
+var DEFAULT_HEALTH_CHECK_STATUSES = []uint32{200, 201, 301, 302}
+
+func commandHealthCheck(command string) *mesos.HealthCheck {
+	return &mesos.HealthCheck{
+		Type: mesos.HealthCheck_COMMAND.Enum(),
+		Command: &mesos.CommandInfo{
+			Value: &command,
+		},
+	}
+}
+
+// mesos performs checks inside the network namespace of the container,
+// so it is the host port for host network and the container port otherwise
+func (builder *TaskBuilder) healthCheckPort(healthCheck *types.HealthCheck) (uint32, error) {
+	docker := builder.task.Slot.Version.Container.Docker
+
+	network := strings.ToLower(docker.Network)
+	if network != "host" && network != "bridge" {
+		// user defined network, no port mappings
+		if healthCheck.Port <= 0 {
+			return 0, fmt.Errorf("no port provided for health check on network %s", docker.Network)
+		}
+		return uint32(healthCheck.Port), nil
+	}
+
+	for index, portMapping := range docker.PortMappings {
+		if portMapping.Name != healthCheck.PortName {
+			continue
+		}
+
+		if network == "host" {
+			if index < len(builder.HostPorts) {
+				return uint32(builder.HostPorts[index]), nil
+			}
+			return uint32(portMapping.HostPort), nil
+		}
+
+		return uint32(portMapping.ContainerPort), nil
+	}
+
+	return 0, fmt.Errorf("port %s of health check not found in port mappings", healthCheck.PortName)
+}
+
+// the same curl options as mesos http health check, requires curl in the image
+func httpCheckCommand(scheme string, port uint32, healthCheck *types.HealthCheck) string {
+	statuses := healthCheck.Statuses
+	if len(statuses) == 0 {
+		statuses = DEFAULT_HEALTH_CHECK_STATUSES
+	}
+
+	codes := make([]string, 0)
+	for _, status := range statuses {
+		codes = append(codes, fmt.Sprintf("%d", status))
+	}
+
+	args := []string{"curl", "-s", "-S", "-L", "-k", "-o", "/dev/null", "-w", shellQuote("%{http_code}")}
+	if healthCheck.TimeoutSeconds > 0 {
+		args = append(args, "--max-time", fmt.Sprintf("%v", healthCheck.TimeoutSeconds))
+	}
+
+	// sorted for the same command of the same version
+	names := make([]string, 0)
+	for name := range healthCheck.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-H", shellQuote(fmt.Sprintf("%s: %s", name, healthCheck.Headers[name])))
+	}
+
+	path := healthCheck.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	args = append(args, shellQuote(fmt.Sprintf("%s://127.0.0.1:%d%s", scheme, port, path)))
+
+	return fmt.Sprintf("%s | grep -qxE %s", strings.Join(args, " "), shellQuote(strings.Join(codes, "|")))
+}
+
+// grpc health checking protocol by grpc_health_probe, requires it in the image
+func grpcCheckCommand(port uint32, healthCheck *types.HealthCheck) string {
+	args := []string{"grpc_health_probe", fmt.Sprintf("-addr=127.0.0.1:%d", port)}
+	if healthCheck.Service != "" {
+		args = append(args, "-service="+shellQuote(healthCheck.Service))
+	}
+	if healthCheck.TimeoutSeconds > 0 {
+		timeout := time.Duration(healthCheck.TimeoutSeconds * float64(time.Second))
+		args = append(args, "-connect-timeout="+timeout.String(), "-rpc-timeout="+timeout.String())
+	}
+
+	return strings.Join(args, " ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpCheckCommand(t *testing.T) {
+	hc := &types.HealthCheck{
+		Path:           "ping",
+		TimeoutSeconds: 3,
+		Statuses:       []uint32{200, 204},
+		Headers:        map[string]string{"X-Token": "it's", "Host": "demo"},
+	}
+
+	assert.Equal(t,
+		`curl -s -S -L -k -o /dev/null -w '%{http_code}' --max-time 3 -H 'Host: demo' -H 'X-Token: it'\''s' 'https://127.0.0.1:8443/ping' | grep -qxE '200|204'`,
+		httpCheckCommand("https", 8443, hc))
+}
+
+func TestGrpcCheckCommand(t *testing.T) {
+	assert.Equal(t, "grpc_health_probe -addr=127.0.0.1:50051", grpcCheckCommand(50051, &types.HealthCheck{}))
+	assert.Equal(t, "grpc_health_probe -addr=127.0.0.1:50051 -service='echo' -connect-timeout=1.5s -rpc-timeout=1.5s",
+		grpcCheckCommand(50051, &types.HealthCheck{Service: "echo", TimeoutSeconds: 1.5}))
+}
+
+func TestHealthCheckPort(t *testing.T) {
+	version := &types.Version{
+		Container: &types.Container{
+			Docker: &types.Docker{
+				Network: "host",
+				PortMappings: []*types.PortMapping{
+					{Name: "web"},
+					{Name: "admin", HostPort: 9090},
+				},
+			},
+		},
+	}
+	builder := &TaskBuilder{
+		task:      &Task{Slot: &Slot{Version: version}},
+		HostPorts: []uint64{31000, 9090},
+	}
+
+	port, err := builder.healthCheckPort(&types.HealthCheck{PortName: "web"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(31000), port)
+
+	_, err = builder.healthCheckPort(&types.HealthCheck{PortName: "none"})
+	assert.NotNil(t, err)
+
+	version.Container.Docker.Network = "swan"
+	_, err = builder.healthCheckPort(&types.HealthCheck{})
+	assert.NotNil(t, err)
+
+	port, err = builder.healthCheckPort(&types.HealthCheck{Port: 8080})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(8080), port)
+}
@@ -0,0 +1,92 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordHealthCheck(t *testing.T) {
+	slot := &Slot{}
+	assert.Nil(t, slot.Health())
+
+	start := time.Now()
+	slot.RecordHealthCheck(true, start, "")
+	slot.RecordHealthCheck(false, start.Add(time.Second), "timeout")
+	slot.RecordHealthCheck(false, start.Add(2*time.Second), "timeout")
+
+	health := slot.Health()
+	assert.False(t, health.Healthy)
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, start.Add(time.Second), *health.LastTransition)
+	assert.Equal(t, start.Add(2*time.Second), *health.LastCheck)
+	assert.Len(t, health.Outcomes, 3)
+
+	for i := 0; i < HEALTH_OUTCOMES_KEPT; i++ {
+		slot.RecordHealthCheck(true, start.Add(time.Duration(3+i)*time.Second), "")
+	}
+
+	health = slot.Health()
+	assert.Equal(t, 0, health.ConsecutiveFailures)
+	assert.Equal(t, start.Add(3*time.Second), *health.LastTransition)
+	assert.Len(t, health.Outcomes, HEALTH_OUTCOMES_KEPT)
+
+	slot.resetHealth()
+	assert.Nil(t, slot.Health())
+}
+
+func TestWatchUnhealthy(t *testing.T) {
+	slot := &Slot{
+		State: SLOT_STATE_TASK_RUNNING,
+		Version: &types.Version{
+			HealthCheck: &types.HealthCheck{KillAfterUnhealthySeconds: 100},
+		},
+	}
+
+	slot.watchUnhealthy()
+	assert.Nil(t, slot.unhealthyTimer)
+
+	slot.RecordHealthCheck(false, time.Now(), "")
+	slot.watchUnhealthy()
+	assert.NotNil(t, slot.unhealthyTimer)
+
+	slot.RecordHealthCheck(true, time.Now(), "")
+	slot.watchUnhealthy()
+	assert.Nil(t, slot.unhealthyTimer)
+
+	slot.RecordHealthCheck(false, time.Now(), "")
+	slot.State = SLOT_STATE_TASK_KILLED
+	slot.watchUnhealthy()
+	assert.Nil(t, slot.unhealthyTimer)
+}
+
+func TestKillUnhealthyAfterUpdate(t *testing.T) {
+	app := newTestApp(t, testVersion(60))
+
+	proposed := testVersion(60)
+	proposed.AppVersion = "v2"
+	proposed.MaxRestarts = 1
+	proposed.HealthCheck = &types.HealthCheck{Protocol: "cmd", Value: "true", KillAfterUnhealthySeconds: 0.01}
+	assert.Nil(t, app.Update(proposed))
+
+	slot, _ := app.GetSlot(0)
+	slot.SetState(SLOT_STATE_TASK_KILLED)
+	runSlot(slot)
+	assert.True(t, app.StateIs(APP_STATE_NORMAL))
+
+	slot.RecordHealthCheck(false, time.Now(), "timeout")
+	slot.SetHealthy(false)
+	assert.True(t, waitFor(func() bool { return slot.unhealthyKilled }))
+
+	// out of restarts, the task is left running
+	slot.DispatchNewTask(slot.Version)
+	runSlot(slot)
+	slot.restartPolicy.restarts = 1
+	slot.RecordHealthCheck(false, time.Now(), "timeout")
+	slot.SetHealthy(false)
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, slot.unhealthyKilled)
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"time"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	DEFAULT_PRE_STOP_TIMEOUT_SECONDS = 5
+)
+
+// used by rolling update and scale down, running task with preStop specified
+// is drained from proxy and dns before killed, the others are killed at once
+func (slot *Slot) DrainAndKillTask() {
+	preStop := slot.Version.PreStop
+	if preStop == nil || !slot.StateIs(SLOT_STATE_TASK_RUNNING) {
+		slot.KillTask()
+		return
+	}
+
+	slot.StopRestartPolicy()
+	slot.draining = true
+	slot.SetState(SLOT_STATE_PENDING_KILL)
+
+	logrus.Infof("draining slot %s for %v seconds before killed", slot.ID, preStop.DrainSeconds)
+
+	slot.SetWeight(0)
+	slot.EmitTaskEvent(eventbus.EventTypeTaskUnhealthy)
+
+	go slot.preStop(slot.CurrentTask)
+
+	slot.Touch()
+}
+
+func (slot *Slot) preStop(task *Task) {
+	preStop := task.Version.PreStop
+	deadline := time.Now().Add(time.Duration(preStop.DrainSeconds * float64(time.Second)))
+
+	if preStop.Path != "" {
+		timeout := time.Duration(DEFAULT_PRE_STOP_TIMEOUT_SECONDS) * time.Second
+		if preStop.TimeoutSeconds > 0 {
+			timeout = time.Duration(preStop.TimeoutSeconds * float64(time.Second))
+		}
+
+		// the task is killed anyway
+		if err := httpGet(slot.portAddress(task, preStop.PortName), preStop.Path, timeout); err != nil {
+			logrus.Warnf("preStop hook of slot %s failed: %s", slot.ID, err.Error())
+		}
+	}
+
+	time.Sleep(deadline.Sub(time.Now()))
+
+	if slot.CurrentTask != task {
+		return
+	}
+
+	slot.draining = false
+
+	// the task might be gone by itself while draining
+	if slot.StateIs(SLOT_STATE_PENDING_KILL) {
+		task.Kill()
+	}
+}
+
+// kill call is sent again for task still running in pending kill, unless
+// being drained, which is not known any more after manager failover
+func (slot *Slot) EnsureKilled() {
+	if slot.draining {
+		return
+	}
+
+	slot.CurrentTask.Kill()
+}
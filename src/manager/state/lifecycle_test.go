@@ -0,0 +1,51 @@
+package state
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreStop(t *testing.T) {
+	called := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called <- r.URL.Path
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	hostPort, _ := strconv.ParseUint(port, 10, 64)
+
+	version := &types.Version{
+		Container: &types.Container{
+			Docker: &types.Docker{
+				PortMappings: []*types.PortMapping{{Name: "web"}},
+			},
+		},
+		PreStop: &types.PreStop{PortName: "web", Path: "shutdown", DrainSeconds: 0.1},
+	}
+	task := &Task{Version: version, HostPorts: []uint64{hostPort}}
+	slot := &Slot{
+		Version:       version,
+		CurrentTask:   task,
+		AgentHostName: host,
+		// gone by itself while draining, no kill call sent
+		State:    SLOT_STATE_TASK_FAILED,
+		draining: true,
+	}
+
+	start := time.Now()
+	slot.preStop(task)
+
+	assert.Equal(t, "/shutdown", <-called)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	assert.False(t, slot.draining)
+}
@@ -0,0 +1,55 @@
+package state
+
+import (
+	"fmt"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	TASK_REASON_AGENT_MAINTENANCE = "REASON_AGENT_MAINTENANCE"
+)
+
+// task which would be relaunched by restart policy elsewhere, fixed ip slots
+// are bound to their agents
+func (slot *Slot) Migratable() bool {
+	return !slot.App.IsFixed() && slot.restartable()
+}
+
+func (slot *Slot) Migrating() bool {
+	return slot.migratedFrom != ""
+}
+
+// task is killed only, the slot is relaunched by restart policy on agents
+// not going into maintenance
+func (slot *Slot) Migrate(agentID string) {
+	slot.migratedFrom = agentID
+
+	reason := fmt.Sprintf("agent %s going into maintenance", agentID)
+	logrus.Warnf("kill task of slot %s: %s", slot.ID, reason)
+
+	e := slot.BuildTaskEvent(eventbus.EventTypeTaskMigrated)
+	e.Payload.(*types.TaskInfoEvent).Reason = reason
+	eventbus.WriteEvent(e)
+
+	// relaunched without backoff of previous failures
+	slot.StartRestartPolicy()
+
+	slot.CurrentTask.Kill()
+
+	slot.Touch()
+}
+
+// reason of the migrated task is recorded when archived
+func (slot *Slot) recordMigration(task *Task) {
+	if slot.migratedFrom == "" {
+		return
+	}
+
+	task.Reason = TASK_REASON_AGENT_MAINTENANCE
+	task.Message = "moved off agent " + slot.migratedFrom + " for maintenance"
+	slot.migratedFrom = ""
+}
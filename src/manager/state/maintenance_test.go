@@ -0,0 +1,24 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigratableAfterUpdate(t *testing.T) {
+	app := newTestApp(t, testVersion(60))
+
+	proposed := testVersion(60)
+	proposed.AppVersion = "v2"
+	assert.Nil(t, app.Update(proposed))
+
+	slot, _ := app.GetSlot(0)
+	slot.SetState(SLOT_STATE_TASK_KILLED)
+	runSlot(slot)
+	assert.True(t, app.StateIs(APP_STATE_NORMAL))
+	assert.True(t, slot.Migratable())
+
+	slot.Version.RestartPolicy = RESTART_POLICY_NEVER
+	assert.False(t, slot.Migratable())
+}
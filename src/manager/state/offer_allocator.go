@@ -2,7 +2,9 @@ package state
 
 import (
 	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/Dataman-Cloud/swan/src/manager/store"
 	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
@@ -13,6 +15,11 @@ import (
 var instance *OfferAllocator
 var once sync.Once
 
+const (
+	// rescinds arrived before their offers are handled are kept this long
+	RESCINDED_OFFER_TTL = time.Minute
+)
+
 type OfferInfo struct {
 	OfferID  string
 	AgentID  string
@@ -21,12 +28,19 @@ type OfferInfo struct {
 }
 
 type OfferAllocator struct {
+	// ordered by priority of app, then by how long the slot has been waiting
 	PendingOfferSlots  []*Slot
 	pendingOfferRWLock sync.RWMutex
 
 	// slotid -> offerinfo
 	AllocatedOffer map[string]*OfferInfo // we store every offer that are occupied by running slot
 	mu             sync.RWMutex
+
+	// offers being matched with pending slots, offer id -> rescinded
+	inflightOffers map[string]bool
+	// rescinds of offers not handled yet, offer id -> rescinded at
+	rescindedOffers map[string]time.Time
+	inflightLock    sync.Mutex
 }
 
 func OfferAllocatorInstance() *OfferAllocator {
@@ -34,6 +48,8 @@ func OfferAllocatorInstance() *OfferAllocator {
 		instance = &OfferAllocator{
 			PendingOfferSlots: make([]*Slot, 0),
 			AllocatedOffer:    make(map[string]*OfferInfo),
+			inflightOffers:    make(map[string]bool),
+			rescindedOffers:   make(map[string]time.Time),
 		}
 	})
 
@@ -41,22 +57,65 @@ func OfferAllocatorInstance() *OfferAllocator {
 }
 
 func (allocator *OfferAllocator) ShiftNextPendingOffer() *Slot {
+	allocator.pendingOfferRWLock.Lock()
+	defer allocator.pendingOfferRWLock.Unlock()
+
 	if len(allocator.PendingOfferSlots) == 0 {
 		return nil
 	}
 
 	var slot *Slot
-	allocator.pendingOfferRWLock.Lock()
 	slot, allocator.PendingOfferSlots = allocator.PendingOfferSlots[0], allocator.PendingOfferSlots[1:]
-	allocator.pendingOfferRWLock.Unlock()
 
 	return slot
 }
 
+// the slot is queued behind those of higher or equal priority waiting longer,
+// so slots put back without matching an offer keep their place
 func (allocator *OfferAllocator) PutSlotBackToPendingQueue(slot *Slot) {
 	allocator.pendingOfferRWLock.Lock()
-	allocator.PendingOfferSlots = append(allocator.PendingOfferSlots, slot)
-	allocator.pendingOfferRWLock.Unlock()
+	defer allocator.pendingOfferRWLock.Unlock()
+
+	slots := allocator.PendingOfferSlots
+	i := sort.Search(len(slots), func(i int) bool {
+		return pendingBefore(slot, slots[i])
+	})
+
+	slots = append(slots, nil)
+	copy(slots[i+1:], slots[i:])
+	slots[i] = slot
+
+	allocator.PendingOfferSlots = slots
+}
+
+// snapshot of pending slots in queue order
+func (allocator *OfferAllocator) PendingSlots() []*Slot {
+	allocator.pendingOfferRWLock.RLock()
+	defer allocator.pendingOfferRWLock.RUnlock()
+
+	return append([]*Slot{}, allocator.PendingOfferSlots...)
+}
+
+// number of pending slots by priority
+func (allocator *OfferAllocator) PendingOfferDepths() map[int32]int {
+	allocator.pendingOfferRWLock.RLock()
+	defer allocator.pendingOfferRWLock.RUnlock()
+
+	depths := make(map[int32]int)
+	for _, slot := range allocator.PendingOfferSlots {
+		depths[slot.Version.Priority] += 1
+	}
+
+	return depths
+}
+
+// higher priority first, the earlier dispatched first for the same priority
+func pendingBefore(a, b *Slot) bool {
+	if a.Version.Priority != b.Version.Priority {
+		return a.Version.Priority > b.Version.Priority
+	}
+
+	return a.CurrentTask.Created.Before(b.CurrentTask.Created)
 }
 
 func (allocator *OfferAllocator) RemoveSlotFromPendingOfferQueue(slot *Slot) {
@@ -79,6 +138,50 @@ func (allocator *OfferAllocator) RemoveSlotFromPendingOfferQueue(slot *Slot) {
 	allocator.PendingOfferSlots = append(allocator.PendingOfferSlots[:slotIndex], allocator.PendingOfferSlots[slotIndex+1:]...)
 }
 
+// called before slots reserve resources of the offer, events are handled
+// concurrently so the rescind of the offer might arrive at any time
+func (allocator *OfferAllocator) BeginOffer(offerID string) {
+	allocator.inflightLock.Lock()
+	defer allocator.inflightLock.Unlock()
+
+	_, rescinded := allocator.rescindedOffers[offerID]
+	delete(allocator.rescindedOffers, offerID)
+
+	allocator.inflightOffers[offerID] = rescinded
+}
+
+// called before the offer is accepted, reservations made on a rescinded
+// offer must be undone instead
+func (allocator *OfferAllocator) FinishOffer(offerID string) (rescinded bool) {
+	allocator.inflightLock.Lock()
+	defer allocator.inflightLock.Unlock()
+
+	rescinded = allocator.inflightOffers[offerID]
+	delete(allocator.inflightOffers, offerID)
+
+	return rescinded
+}
+
+// offers already accepted are not tracked, tasks launched on them are
+// reported lost by mesos and relaunched by restart policy
+func (allocator *OfferAllocator) RescindOffer(offerID string) {
+	allocator.inflightLock.Lock()
+	defer allocator.inflightLock.Unlock()
+
+	if _, found := allocator.inflightOffers[offerID]; found {
+		allocator.inflightOffers[offerID] = true
+		return
+	}
+
+	now := time.Now()
+	for id, rescindedAt := range allocator.rescindedOffers {
+		if now.Sub(rescindedAt) > RESCINDED_OFFER_TTL {
+			delete(allocator.rescindedOffers, id)
+		}
+	}
+	allocator.rescindedOffers[offerID] = now
+}
+
 // NOTE Lock & raft write may cause performance problems
 func (allocator *OfferAllocator) SetOfferSlotMap(offer *mesos.Offer, slot *Slot) {
 	allocator.mu.Lock()
@@ -101,6 +204,26 @@ func (allocator *OfferAllocator) RemoveOfferSlotMapBySlot(slot *Slot) {
 	allocator.mu.Unlock()
 }
 
+// slot ids changed as slots reindexed, old id -> new id
+func (allocator *OfferAllocator) ReindexOfferSlotMap(ids map[string]string) {
+	allocator.mu.Lock()
+	defer allocator.mu.Unlock()
+
+	infos := make(map[string]*OfferInfo)
+	for oldID, newID := range ids {
+		if info, found := allocator.AllocatedOffer[oldID]; found {
+			infos[newID] = info
+			allocator.remove(oldID)
+			delete(allocator.AllocatedOffer, oldID)
+		}
+	}
+
+	for newID, info := range infos {
+		allocator.create(newID, info)
+		allocator.AllocatedOffer[newID] = info
+	}
+}
+
 func (allocator *OfferAllocator) RemoveOfferSlotMapByOfferId(offerId string) {
 	allocator.mu.Lock()
 	defer allocator.mu.Unlock()
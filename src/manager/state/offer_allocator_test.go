@@ -0,0 +1,67 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pendingSlot(id string, priority int32, created time.Time) *Slot {
+	return &Slot{
+		ID:          id,
+		Version:     &types.Version{Priority: priority},
+		CurrentTask: &Task{Created: created},
+	}
+}
+
+func TestPendingOfferQueue(t *testing.T) {
+	now := time.Now()
+	allocator := &OfferAllocator{PendingOfferSlots: make([]*Slot, 0)}
+
+	allocator.PutSlotBackToPendingQueue(pendingSlot("low-1", 0, now.Add(-time.Hour)))
+	allocator.PutSlotBackToPendingQueue(pendingSlot("low-0", 0, now.Add(-2*time.Hour)))
+	allocator.PutSlotBackToPendingQueue(pendingSlot("critical", 10, now))
+	allocator.PutSlotBackToPendingQueue(pendingSlot("low-2", 0, now))
+
+	assert.Equal(t, map[int32]int{0: 3, 10: 1}, allocator.PendingOfferDepths())
+
+	// slot put back keeps its place
+	slot := allocator.ShiftNextPendingOffer()
+	assert.Equal(t, "critical", slot.ID)
+	allocator.PutSlotBackToPendingQueue(slot)
+
+	ids := make([]string, 0)
+	for slot := allocator.ShiftNextPendingOffer(); slot != nil; slot = allocator.ShiftNextPendingOffer() {
+		ids = append(ids, slot.ID)
+	}
+	assert.Equal(t, []string{"critical", "low-0", "low-1", "low-2"}, ids)
+}
+
+func TestRescindOffer(t *testing.T) {
+	allocator := &OfferAllocator{
+		inflightOffers:  make(map[string]bool),
+		rescindedOffers: make(map[string]time.Time),
+	}
+
+	allocator.BeginOffer("offer-0")
+	assert.False(t, allocator.FinishOffer("offer-0"))
+
+	// rescinded while being handled
+	allocator.BeginOffer("offer-1")
+	allocator.RescindOffer("offer-1")
+	assert.True(t, allocator.FinishOffer("offer-1"))
+
+	// rescinded before being handled
+	allocator.RescindOffer("offer-2")
+	allocator.BeginOffer("offer-2")
+	assert.True(t, allocator.FinishOffer("offer-2"))
+
+	// stale rescinds are dropped
+	allocator.rescindedOffers["offer-3"] = time.Now().Add(-2 * RESCINDED_OFFER_TTL)
+	allocator.RescindOffer("offer-4")
+	assert.NotContains(t, allocator.rescindedOffers, "offer-3")
+	assert.Len(t, allocator.inflightOffers, 0)
+}
@@ -0,0 +1,57 @@
+package state
+
+import (
+	"fmt"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	TASK_REASON_PREEMPTED = "REASON_PREEMPTED"
+)
+
+// running task which would be relaunched by restart policy, of app not being
+// updated or scaled, fixed ip slots can't be placed elsewhere
+func (slot *Slot) Preemptible() bool {
+	return slot.App.StateIs(APP_STATE_NORMAL) &&
+		!slot.App.IsFixed() &&
+		!slot.Version.NonPreemptible &&
+		slot.StateIs(SLOT_STATE_TASK_RUNNING) &&
+		slot.restartable() &&
+		slot.preemptedBy == "" &&
+		!slot.Migrating()
+}
+
+// task is killed only, the slot is relaunched by restart policy and waits
+// in pending queue again
+func (slot *Slot) Preempt(by *Slot) {
+	slot.preemptedBy = by.ID
+
+	reason := fmt.Sprintf("preempted by slot %s of priority %d", by.ID, by.Version.Priority)
+	logrus.Warnf("kill task of slot %s: %s", slot.ID, reason)
+
+	e := slot.BuildTaskEvent(eventbus.EventTypeTaskPreempted)
+	e.Payload.(*types.TaskInfoEvent).Reason = reason
+	eventbus.WriteEvent(e)
+
+	// relaunched without backoff of previous failures
+	slot.StartRestartPolicy()
+
+	slot.CurrentTask.Kill()
+
+	slot.Touch()
+}
+
+// reason of the preempted task is recorded when archived
+func (slot *Slot) recordPreemption(task *Task) {
+	if slot.preemptedBy == "" {
+		return
+	}
+
+	task.Reason = TASK_REASON_PREEMPTED
+	task.Message = "preempted by slot " + slot.preemptedBy
+	slot.preemptedBy = ""
+}
@@ -0,0 +1,163 @@
+package state
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	DEFAULT_READINESS_INTERVAL_SECONDS = 10
+	DEFAULT_READINESS_TIMEOUT_SECONDS  = 5
+)
+
+// always ready without readiness check, otherwise not until the check of
+// the current task passed once. readiness is never lost after that and
+// failing readiness check never restarts the task
+func (slot *Slot) Ready() bool {
+	return slot.Version.ReadinessCheck == nil || slot.ready
+}
+
+func (slot *Slot) setReady(ready bool) {
+	wasHealthy := slot.Healthy()
+
+	slot.ready = ready
+
+	slot.App.Step() // step forward state-machine
+
+	slot.Touch()
+
+	slot.emitHealthyChange(wasHealthy)
+}
+
+// probe running task which is not ready yet, stop probing for other states
+func (slot *Slot) watchReadiness() {
+	slot.healthLock.Lock()
+	defer slot.healthLock.Unlock()
+
+	check := slot.Version.ReadinessCheck
+	if check == nil || slot.ready || !slot.StateIs(SLOT_STATE_TASK_RUNNING) {
+		if slot.readinessStop != nil {
+			close(slot.readinessStop)
+			slot.readinessStop = nil
+		}
+		return
+	}
+
+	if slot.readinessStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	slot.readinessStop = stop
+
+	go slot.probeReadiness(slot.CurrentTask, check, stop)
+}
+
+func (slot *Slot) probeReadiness(task *Task, check *types.ReadinessCheck, stop chan struct{}) {
+	interval := time.Duration(DEFAULT_READINESS_INTERVAL_SECONDS) * time.Second
+	if check.IntervalSeconds > 0 {
+		interval = time.Duration(check.IntervalSeconds * float64(time.Second))
+	}
+
+	timeout := time.Duration(DEFAULT_READINESS_TIMEOUT_SECONDS) * time.Second
+	if check.TimeoutSeconds > 0 {
+		timeout = time.Duration(check.TimeoutSeconds * float64(time.Second))
+	}
+
+	select {
+	case <-stop:
+		return
+	case <-time.After(time.Duration(check.DelaySeconds * float64(time.Second))):
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := probeReadinessOnce(slot.portAddress(task, check.PortName), check, timeout)
+		if err == nil {
+			// the task might be replaced or stopped while probing
+			slot.healthLock.Lock()
+			current := slot.readinessStop == stop && slot.CurrentTask == task
+			if current {
+				slot.readinessStop = nil
+			}
+			slot.healthLock.Unlock()
+
+			if current {
+				logrus.Infof("slot %s passed readiness check", slot.ID)
+				slot.setReady(true)
+			}
+			return
+		}
+
+		logrus.Debugf("slot %s not ready: %s", slot.ID, err.Error())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// host port mapped on agent, only replicates app has port mappings
+func (slot *Slot) portAddress(task *Task, portName string) string {
+	for i, pm := range slot.Version.Container.Docker.PortMappings {
+		if pm.Name != portName {
+			continue
+		}
+
+		if i < len(task.HostPorts) {
+			return net.JoinHostPort(slot.AgentHostName, strconv.FormatUint(task.HostPorts[i], 10))
+		}
+	}
+
+	return ""
+}
+
+func probeReadinessOnce(addr string, check *types.ReadinessCheck, timeout time.Duration) error {
+	if addr == "" {
+		return fmt.Errorf("port %s not found", check.PortName)
+	}
+
+	switch strings.ToLower(check.Protocol) {
+	case "tcp":
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case "http":
+		return httpGet(addr, check.Path, timeout)
+	default:
+		return fmt.Errorf("unsupported readiness check protocol %s", check.Protocol)
+	}
+}
+
+// any status in 2xx and 3xx means success
+func httpGet(addr, path string, timeout time.Duration) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get("http://" + addr + path)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status %d of %s", resp.StatusCode, path)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package state
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotReady(t *testing.T) {
+	slot := &Slot{Version: &types.Version{}, healthy: true}
+	assert.True(t, slot.Ready())
+	assert.True(t, slot.Healthy())
+
+	slot.Version.ReadinessCheck = &types.ReadinessCheck{Protocol: "tcp", PortName: "web"}
+	assert.False(t, slot.Ready())
+	assert.False(t, slot.Healthy())
+
+	slot.ready = true
+	assert.True(t, slot.Healthy())
+}
+
+func TestProbeReadinessOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	assert.Nil(t, probeReadinessOnce(addr, &types.ReadinessCheck{Protocol: "http", Path: "/ready"}, time.Second))
+	assert.NotNil(t, probeReadinessOnce(addr, &types.ReadinessCheck{Protocol: "http", Path: "/warming"}, time.Second))
+	assert.Nil(t, probeReadinessOnce(addr, &types.ReadinessCheck{Protocol: "TCP"}, time.Second))
+	assert.NotNil(t, probeReadinessOnce("", &types.ReadinessCheck{Protocol: "tcp"}, time.Second))
+}
@@ -23,6 +23,7 @@ func LoadAppData(userEventChan chan *event.UserEvent) map[string]*App {
 			Created:   time.Unix(0, raftApp.CreatedAt),
 			Updated:   time.Unix(0, raftApp.UpdatedAt),
 			Slots:     make(map[int]*Slot),
+			Job:       raftApp.Job,
 		}
 
 		app.UserEventChan = userEventChan
@@ -50,6 +51,13 @@ func LoadAppData(userEventChan chan *event.UserEvent) map[string]*App {
 
 		app.Versions = versions
 
+		rollbacks := make([]*types.Rollback, 0)
+		for _, raftRollback := range raftApp.Rollbacks {
+			rollbacks = append(rollbacks, RollbackFromRaft(raftRollback))
+		}
+
+		app.Rollbacks = rollbacks
+
 		slots := LoadAppSlots(app)
 		for _, slot := range slots {
 			app.Slots[int(slot.Index)] = slot
@@ -74,7 +82,9 @@ func LoadAppSlots(app *App) []*Slot {
 		raftTasks := store.DB().ListTaskHistory(app.ID, slot.ID)
 		var tasks []*Task
 		for _, raftTask := range raftTasks {
-			tasks = append(tasks, TaskFromRaft(raftTask, app))
+			task := TaskFromRaft(raftTask, app)
+			task.Slot = slot
+			tasks = append(tasks, task)
 		}
 		slot.TaskHistory = tasks
 
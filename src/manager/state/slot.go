@@ -7,6 +7,7 @@ import (
 	"time"
 
 	eventbus "github.com/Dataman-Cloud/swan/src/event"
+	"github.com/Dataman-Cloud/swan/src/manager/connector"
 	"github.com/Dataman-Cloud/swan/src/manager/store"
 	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
 	"github.com/Dataman-Cloud/swan/src/types"
@@ -78,6 +79,23 @@ type Slot struct {
 	restartPolicy *RestartPolicy
 
 	healthy bool
+	// health check results of the current task
+	health         *types.TaskHealth
+	unhealthyTimer *time.Timer
+	// task killed for being unhealthy, waiting for mesos to confirm
+	unhealthyKilled bool
+	healthLock      sync.Mutex
+	// readiness check of the current task passed
+	ready         bool
+	readinessStop chan struct{}
+	// slot takes no traffic, eg. launched by blue/green update but not flipped yet
+	standby bool
+	// waiting for proxy and dns draining traffic before killed
+	draining bool
+	// task killed for the pending slot of higher priority
+	preemptedBy string
+	// task killed to move off the agent going into maintenance
+	migratedFrom string
 }
 
 type SlotsById []*Slot
@@ -103,33 +121,50 @@ func NewSlot(app *App, version *types.Version, index int) *Slot {
 		resourceReservationLock: sync.Mutex{},
 	}
 
-	if version.Gateway != nil && version.Gateway.Weight > 0 {
-		slot.weight = version.Gateway.Weight
-	} else {
-		slot.weight = DEFUALT_WEIGHT
-	}
+	slot.weight = defaultWeight(version)
 
 	if slot.App.IsFixed() {
 		slot.Ip = app.CurrentVersion.IP[index]
 	}
 
-	// initialize restart policy
+	slot.StartRestartPolicy()
+
+	slot.create()
+
+	return slot
+}
+
+func (slot *Slot) StartRestartPolicy() {
+	slot.StopRestartPolicy()
+
+	if slot.Version.RestartPolicy == RESTART_POLICY_NEVER {
+		return
+	}
+
 	testAndRestartFunc := func(s *Slot) bool {
-		if slot.Abnormal() {
-			s.Archive()
-			s.DispatchNewTask(slot.Version)
+		if !s.Abnormal() {
+			return false
 		}
 
-		return false
-	}
+		// finite tasks which exit successfully are left finished
+		if s.Version.RestartPolicy == RESTART_POLICY_ON_FAILURE && s.StateIs(SLOT_STATE_TASK_FINISHED) {
+			return false
+		}
 
-	//slot.restartPolicy = NewRestartPolicy(slot, slot.Version.BackoffSeconds,
-	//slot.Version.BackoffFactor, slot.Version.MaxLaunchDelaySeconds, testAndRestartFunc)
-	slot.restartPolicy = NewRestartPolicy(slot, time.Second*10, 1, time.Second*300, testAndRestartFunc)
+		s.Archive()
+		s.DispatchNewTask(s.Version)
 
-	slot.create()
+		return true
+	}
 
-	return slot
+	slot.restartPolicy = NewRestartPolicy(slot, slot.Version, testAndRestartFunc)
+}
+
+// task gone is relaunched by restart policy, decided by the version as the
+// policy itself is stopped while the task killed by app state machine and is
+// re-armed once relaunched
+func (slot *Slot) restartable() bool {
+	return slot.Version.RestartPolicy != RESTART_POLICY_NEVER
 }
 
 // kill task doesn't need cleanup slot from app.Slots
@@ -148,19 +183,44 @@ func (slot *Slot) KillTask() {
 }
 
 func (slot *Slot) Archive() {
-	slot.CurrentTask.ArchivedAt = time.Now()
-	slot.TaskHistory = append(slot.TaskHistory, slot.CurrentTask)
-	//store.DB().UpdateTask(TaskToRaft(slot.CurrentTask))
+	task := slot.CurrentTask
+	task.State = slot.State
+	task.ArchivedAt = time.Now()
+	slot.recordPreemption(task)
+	slot.recordMigration(task)
+
+	slot.TaskHistory = pruneTaskHistory(append(slot.TaskHistory, task), task.ArchivedAt)
+	if err := store.DB().AppendTaskHistory(slot.App.ID, slot.ID, TaskToRaft(task)); err != nil {
+		logrus.Errorf("persist task history of slot %s error: %s", slot.ID, err.Error())
+	}
 
 	slot.Touch()
 }
 
+// the same pruning policy as task history in store
+func pruneTaskHistory(history []*Task, now time.Time) []*Task {
+	if len(history) > store.TASK_HISTORY_LIMIT {
+		history = history[len(history)-store.TASK_HISTORY_LIMIT:]
+	}
+
+	expiredBefore := now.Add(-store.TASK_HISTORY_MAX_AGE)
+	for len(history) > 0 && history[0].ArchivedAt.Before(expiredBefore) {
+		history = history[1:]
+	}
+
+	return history
+}
+
 func (slot *Slot) DispatchNewTask(version *types.Version) {
 	slot.Version = version
 	slot.CurrentTask = NewTask(slot.Version, slot)
+	slot.resetHealth()
+	slot.ready = false
+	slot.draining = false
 	slot.SetState(SLOT_STATE_PENDING_OFFER)
 
 	OfferAllocatorInstance().PutSlotBackToPendingQueue(slot)
+	connector.Instance().ReviveOffers()
 
 	slot.Touch()
 }
@@ -311,6 +371,9 @@ func (slot *Slot) SetState(state string) error {
 	default:
 	}
 
+	slot.watchUnhealthy()
+	slot.watchReadiness()
+
 	// skip app invalidation if slot state is not mesos driven
 	slot.App.Step()
 
@@ -347,6 +410,13 @@ func (slot *Slot) Dispatched() bool {
 }
 
 func (slot *Slot) EmitTaskEvent(eventType string) {
+	// gateway and dns are not aware of standby slots
+	if slot.standby && (eventType == eventbus.EventTypeTaskHealthy ||
+		eventType == eventbus.EventTypeTaskUnhealthy ||
+		eventType == eventbus.EventTypeTaskWeightChange) {
+		return
+	}
+
 	eventbus.WriteEvent(slot.BuildTaskEvent(eventType))
 }
 
@@ -367,7 +437,7 @@ func (slot *Slot) BuildTaskEvent(eventType string) *eventbus.Event {
 		VersionID:      slot.Version.ID,
 		AppVersion:     slot.Version.AppVersion,
 		State:          slot.State,
-		Healthy:        slot.healthy,
+		Healthy:        slot.Healthy(),
 		ClusterID:      slot.App.ClusterID,
 		RunAs:          slot.Version.RunAs,
 		Weight:         slot.weight,
@@ -388,25 +458,41 @@ func (slot *Slot) BuildTaskEvent(eventType string) *eventbus.Event {
 		}
 	}
 
+	if eventType == eventbus.EventTypeTaskHealthy || eventType == eventbus.EventTypeTaskUnhealthy {
+		payload.Health = slot.Health()
+	}
+
 	e.Payload = payload
 
 	return e
 }
 
+// slot takes traffic only if both health check and readiness check passed
 func (slot *Slot) Healthy() bool {
-	return slot.healthy
+	return slot.healthy && slot.Ready()
 }
 
 func (slot *Slot) SetHealthy(healthy bool) {
-	needEmitEvent := slot.healthy != healthy
+	wasHealthy := slot.Healthy()
 
 	slot.healthy = healthy
 
+	slot.watchUnhealthy()
+
 	slot.App.Step() // step forward state-machine
 
 	slot.Touch()
 
-	if needEmitEvent {
+	slot.emitHealthyChange(wasHealthy)
+}
+
+func (slot *Slot) emitHealthyChange(wasHealthy bool) {
+	// drained from proxy and dns already
+	if slot.StateIs(SLOT_STATE_PENDING_KILL) {
+		return
+	}
+
+	if healthy := slot.Healthy(); healthy != wasHealthy {
 		if healthy {
 			slot.EmitTaskEvent(eventbus.EventTypeTaskHealthy)
 		} else {
@@ -420,10 +506,23 @@ func (slot *Slot) SetWeight(newWeight float64) {
 	slot.EmitTaskEvent(eventbus.EventTypeTaskWeightChange)
 }
 
+func (slot *Slot) Standby() bool {
+	return slot.standby
+}
+
 func (slot *Slot) GetWeight() float64 {
 	return slot.weight
 }
 
+// weight of slot specified by gateway of version
+func defaultWeight(version *types.Version) float64 {
+	if version.Gateway != nil && version.Gateway.Weight > 0 {
+		return version.Gateway.Weight
+	}
+
+	return DEFUALT_WEIGHT
+}
+
 func (slot *Slot) Remove() {
 	slot.remove()
 }
@@ -446,6 +545,19 @@ func (slot *Slot) create() {
 	}
 }
 
+// task history is stored under the slot id, carried over when the slot is
+// created again under another id
+func (slot *Slot) createWithHistory() {
+	raftSlot := SlotToRaft(slot)
+	for _, task := range slot.TaskHistory {
+		raftSlot.TaskHistory = append(raftSlot.TaskHistory, TaskToRaft(task))
+	}
+
+	if err := store.DB().CreateSlot(raftSlot); err != nil {
+		logrus.Error(err)
+	}
+}
+
 func (slot *Slot) remove() {
 	err := store.DB().DeleteSlot(slot.App.ID, slot.ID)
 	if err != nil {
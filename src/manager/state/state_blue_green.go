@@ -0,0 +1,192 @@
+package state
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+)
+
+// a full set of standby slots of the proposed version is launched next to the
+// current ones, traffic is flipped to them at once when all of them are healthy.
+type StateBlueGreen struct {
+	Name string
+	App  *App
+
+	// standby slots take temporary indices starting from Offset,
+	// current slots are moved there once traffic flipped
+	Offset int
+	// traffic flipped to standby slots, old slots are being reaped
+	Flipped bool
+
+	lock sync.Mutex
+}
+
+func NewStateBlueGreen(app *App) *StateBlueGreen {
+	return &StateBlueGreen{
+		App:  app,
+		Name: APP_STATE_BLUE_GREEN,
+	}
+}
+
+func (blueGreen *StateBlueGreen) OnEnter() {
+	logrus.Debug("state blueGreen OnEnter")
+
+	blueGreen.App.EmitAppEvent(blueGreen.Name)
+
+	blueGreen.lock.Lock()
+	defer blueGreen.lock.Unlock()
+
+	// indices of the app are always contiguous, so the temporary ones never collide
+	blueGreen.Offset = len(blueGreen.App.GetSlots())
+	blueGreen.Flipped = false
+
+	for i := 0; i < int(blueGreen.App.ProposedVersion.Instances); i++ {
+		slot := NewSlot(blueGreen.App, blueGreen.App.ProposedVersion, blueGreen.Offset+i)
+		slot.standby = true
+		blueGreen.App.SetSlot(slot.Index, slot)
+		slot.DispatchNewTask(blueGreen.App.ProposedVersion)
+	}
+}
+
+func (blueGreen *StateBlueGreen) OnExit() {
+	logrus.Debug("state blueGreen OnExit")
+}
+
+func (blueGreen *StateBlueGreen) Step() {
+	logrus.Debug("state blueGreen step")
+
+	if !blueGreen.Flipped {
+		temporaries := blueGreen.temporarySlots()
+		if len(temporaries) < int(blueGreen.App.ProposedVersion.Instances) {
+			logrus.Info("state blueGreen step, standby slots not all launched")
+			return
+		}
+
+		for _, slot := range temporaries {
+			if !slot.StateIs(SLOT_STATE_TASK_RUNNING) || !slot.Healthy() {
+				logrus.Info("state blueGreen step, waiting standby slots to be healthy")
+				return
+			}
+		}
+
+		blueGreen.flip()
+		return
+	}
+
+	// reap old slots which got killed
+	for _, slot := range blueGreen.temporarySlots() {
+		if slot.StateIs(SLOT_STATE_REAP) || slot.Abnormal() {
+			blueGreen.App.RemoveSlot(slot.Index)
+		}
+	}
+
+	if len(blueGreen.temporarySlots()) == 0 && blueGreen.App.ProposedVersion != nil {
+		logrus.Debug("state blueGreen step, all old slots reaped")
+
+		blueGreen.App.CurrentVersion = blueGreen.App.ProposedVersion
+		blueGreen.App.ProposedVersion = nil
+
+		blueGreen.App.TransitTo(APP_STATE_NORMAL)
+	}
+}
+
+// switch gateway and dns traffic from current slots to standby ones in one step
+func (blueGreen *StateBlueGreen) flip() {
+	blueGreen.lock.Lock()
+	if blueGreen.Flipped {
+		blueGreen.lock.Unlock()
+		return
+	}
+	blueGreen.Flipped = true
+	blueGreen.lock.Unlock()
+
+	logrus.Infof("app %s flipping traffic to %d standby slots", blueGreen.App.ID,
+		blueGreen.App.ProposedVersion.Instances)
+
+	olds := make([]*Slot, 0)
+	standbys := blueGreen.temporarySlots()
+	for _, slot := range blueGreen.App.GetSlots() {
+		if slot.Index < blueGreen.Offset {
+			olds = append(olds, slot)
+		}
+	}
+
+	for _, slot := range olds {
+		slot.EmitTaskEvent(eventbus.EventTypeTaskUnhealthy)
+		slot.standby = true
+	}
+
+	// standby slots take over indices of the old ones
+	indices := make(map[*Slot]int)
+	for i, slot := range olds {
+		indices[slot] = blueGreen.Offset + i
+	}
+	for i, slot := range standbys {
+		indices[slot] = i
+	}
+	blueGreen.App.reindexSlots(indices)
+
+	for _, slot := range standbys {
+		slot.standby = false
+		slot.EmitTaskEvent(eventbus.EventTypeTaskHealthy)
+		slot.Touch()
+	}
+
+	blueGreen.App.Touch()
+
+	for _, slot := range olds {
+		slot.KillTask()
+	}
+}
+
+// abort the update before traffic flipped, standby slots are discarded
+func (blueGreen *StateBlueGreen) Cancel() error {
+	if blueGreen.Flipped {
+		return errors.New("traffic already flipped to proposed version, can not cancel")
+	}
+
+	standbys := blueGreen.temporarySlots()
+
+	blueGreen.App.ProposedVersion = nil
+	if err := blueGreen.App.TransitTo(APP_STATE_NORMAL); err != nil {
+		return err
+	}
+
+	for _, slot := range standbys {
+		slot.KillTask()
+		blueGreen.App.RemoveSlot(slot.Index)
+	}
+
+	return nil
+}
+
+func (blueGreen *StateBlueGreen) temporarySlots() []*Slot {
+	slots := make([]*Slot, 0)
+	for _, slot := range blueGreen.App.GetSlots() {
+		if slot.Index >= blueGreen.Offset {
+			slots = append(slots, slot)
+		}
+	}
+
+	return slots
+}
+
+func (blueGreen *StateBlueGreen) StateName() string {
+	return blueGreen.Name
+}
+
+// slots at temporary indices must be cleaned up before any other transition,
+// except deleting which kills and removes all of them anyway
+func (blueGreen *StateBlueGreen) CanTransitTo(targetState string) bool {
+	logrus.Debugf("state blueGreen CanTransitTo %s", targetState)
+
+	switch targetState {
+	case APP_STATE_NORMAL, APP_STATE_DELETING:
+		return true
+	}
+
+	return false
+}
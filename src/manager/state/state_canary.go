@@ -0,0 +1,287 @@
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+)
+
+const (
+	DEFAULT_CANARY_STEP_INTERVAL = 60
+)
+
+var DefaultCanaryWeightSteps = []float64{5, 25, 50, 100}
+
+// canaries of the proposed version are running, their share of gateway traffic
+// is raised step by step until the update is promoted or aborted
+type StateCanary struct {
+	Name string
+	App  *App
+
+	// index of weight step applied to canaries
+	CanaryStep int
+
+	lock      sync.Mutex
+	stepTimer *time.Timer
+	// gateway requests of canaries when current step began
+	baseline *GatewayRequestStats
+}
+
+func NewStateCanary(app *App) *StateCanary {
+	return &StateCanary{
+		App:  app,
+		Name: APP_STATE_CANARY,
+	}
+}
+
+func (canary *StateCanary) OnEnter() {
+	logrus.Debug("state canary OnEnter")
+
+	canary.App.EmitAppEvent(canary.Name)
+
+	canary.CanaryStep = 0
+	canary.applyWeights()
+	canary.scheduleNextStep()
+}
+
+func (canary *StateCanary) OnExit() {
+	logrus.Debug("state canary OnExit")
+
+	canary.lock.Lock()
+	if canary.stepTimer != nil {
+		canary.stepTimer.Stop()
+	}
+	canary.lock.Unlock()
+
+	// whether promoted or aborted, traffic goes back to be evenly balanced
+	for _, slot := range canary.App.GetSlots() {
+		if slot.GetWeight() != defaultWeight(slot.Version) {
+			slot.SetWeight(defaultWeight(slot.Version))
+		}
+	}
+}
+
+func (canary *StateCanary) Step() {
+	logrus.Debug("state canary step")
+
+	if canary.canariesFailed() {
+		canary.abort()
+		return
+	}
+
+	// timer lost when state recovered from store
+	canary.lock.Lock()
+	lost := canary.stepTimer == nil
+	canary.lock.Unlock()
+	if lost {
+		canary.scheduleNextStep()
+	}
+}
+
+func (canary *StateCanary) StateName() string {
+	return canary.Name
+}
+
+func (canary *StateCanary) CanTransitTo(targetState string) bool {
+	logrus.Debugf("state canary CanTransitTo %s", targetState)
+
+	switch targetState {
+	case APP_STATE_UPDATING, APP_STATE_CANCEL_UPDATE, APP_STATE_DELETING:
+		return true
+	}
+
+	return false
+}
+
+func (canary *StateCanary) policy() *types.CanaryPolicy {
+	if policy := canary.App.ProposedVersion.UpdatePolicy.Canary; policy != nil {
+		return policy
+	}
+
+	return &types.CanaryPolicy{}
+}
+
+func (canary *StateCanary) weightSteps() []float64 {
+	if steps := canary.policy().WeightSteps; len(steps) > 0 {
+		return steps
+	}
+
+	return DefaultCanaryWeightSteps
+}
+
+func (canary *StateCanary) canaries() []*Slot {
+	canaries := make([]*Slot, 0)
+	for _, slot := range canary.App.GetSlots() {
+		if slot.Version.ID == canary.App.ProposedVersion.ID {
+			canaries = append(canaries, slot)
+		}
+	}
+
+	return canaries
+}
+
+func (canary *StateCanary) canariesFailed() bool {
+	for _, slot := range canary.canaries() {
+		if slot.Abnormal() || (slot.StateIs(SLOT_STATE_TASK_RUNNING) && !slot.Healthy()) {
+			logrus.Warnf("canary %s of app %s failed with state %s", slot.ID, canary.App.ID, slot.State)
+			return true
+		}
+	}
+
+	return false
+}
+
+// route percentage of current step to canaries, weights of the others are
+// left untouched unless all traffic goes to canaries
+func (canary *StateCanary) applyWeights() {
+	canaries := canary.canaries()
+	if len(canaries) == 0 {
+		return
+	}
+
+	percent := canary.weightSteps()[canary.CanaryStep]
+
+	others := make([]*Slot, 0)
+	othersWeight := float64(0)
+	for _, slot := range canary.App.GetSlots() {
+		if slot.Version.ID != canary.App.ProposedVersion.ID {
+			others = append(others, slot)
+			othersWeight += defaultWeight(slot.Version)
+		}
+	}
+
+	canaryWeight := defaultWeight(canary.App.ProposedVersion)
+	if percent < 100 && len(others) > 0 {
+		canaryWeight = othersWeight * percent / (100 - percent) / float64(len(canaries))
+	}
+
+	logrus.Infof("app %s routing %.1f%% traffic to %d canaries with weight %.2f",
+		canary.App.ID, percent, len(canaries), canaryWeight)
+
+	for _, slot := range canaries {
+		slot.SetWeight(canaryWeight)
+	}
+
+	if percent >= 100 {
+		for _, slot := range others {
+			slot.SetWeight(0)
+		}
+	}
+}
+
+func (canary *StateCanary) scheduleNextStep() {
+	canary.lock.Lock()
+	defer canary.lock.Unlock()
+
+	if canary.stepTimer != nil {
+		canary.stepTimer.Stop()
+	}
+
+	canary.baseline = canary.fetchRequestStats()
+
+	interval := canary.policy().StepInterval
+	if interval <= 0 {
+		interval = DEFAULT_CANARY_STEP_INTERVAL
+	}
+
+	canary.stepTimer = time.AfterFunc(time.Duration(interval)*time.Second, canary.evaluate)
+}
+
+// nil returned if error check disabled or gateway metrics not available
+func (canary *StateCanary) fetchRequestStats() *GatewayRequestStats {
+	if canary.policy().MaxErrorRate <= 0 || len(GatewayMetricsAddrs()) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0)
+	for _, slot := range canary.canaries() {
+		ids = append(ids, slot.ID)
+	}
+
+	stats, err := FetchGatewayRequestStats(ids)
+	if err != nil {
+		logrus.Errorf("fetch gateway requests of app %s canaries failed: %s", canary.App.ID, err.Error())
+		return nil
+	}
+
+	return stats
+}
+
+func (canary *StateCanary) evaluate() {
+	// update canceled or proceeded by user in the meantime
+	if canary.App.StateMachine.CurrentState() != canary {
+		return
+	}
+
+	if canary.canariesFailed() {
+		canary.abort()
+		return
+	}
+
+	canary.lock.Lock()
+	baseline := canary.baseline
+	canary.lock.Unlock()
+
+	if baseline != nil {
+		if stats := canary.fetchRequestStats(); stats != nil {
+			rate := stats.ErrorRateSince(baseline)
+			if rate > canary.policy().MaxErrorRate {
+				logrus.Warnf("canaries of app %s got gateway error rate %.4f, exceed %.4f",
+					canary.App.ID, rate, canary.policy().MaxErrorRate)
+				canary.abort()
+				return
+			}
+		}
+	}
+
+	if canary.CanaryStep >= len(canary.weightSteps())-1 {
+		canary.promote()
+		return
+	}
+
+	canary.CanaryStep += 1
+	canary.applyWeights()
+	canary.App.Touch()
+
+	canary.scheduleNextStep()
+}
+
+// continue rolling update on the remaining slots
+func (canary *StateCanary) promote() {
+	remains := len(canary.App.GetSlots()) - len(canary.canaries())
+	slotCountNeedUpdate := remains
+	if policy := canary.App.ProposedVersion.UpdatePolicy; policy.BatchSize > 0 && int(policy.BatchSize) < remains {
+		slotCountNeedUpdate = int(policy.BatchSize)
+	}
+
+	logrus.Infof("app %s canaries promoted, updating next %d slots", canary.App.ID, slotCountNeedUpdate)
+
+	if err := canary.App.TransitTo(APP_STATE_UPDATING, slotCountNeedUpdate); err != nil {
+		logrus.Errorf("promote canaries of app %s failed: %s", canary.App.ID, err.Error())
+	}
+}
+
+func (canary *StateCanary) abort() {
+	logrus.Warnf("app %s canaries aborted, rolling back", canary.App.ID)
+
+	if err := canary.App.TransitTo(APP_STATE_CANCEL_UPDATE); err != nil {
+		logrus.Errorf("abort canaries of app %s failed: %s", canary.App.ID, err.Error())
+	}
+}
+
+// count of canaries if proposed version updated with canary strategy
+func canaryInstances(version *types.Version) int {
+	policy := version.UpdatePolicy
+	if policy == nil || policy.Strategy != UPDATE_STRATEGY_CANARY {
+		return 0
+	}
+
+	if policy.Canary == nil || policy.Canary.Instances <= 0 {
+		return 1
+	}
+
+	return int(policy.Canary.Instances)
+}
@@ -29,6 +29,8 @@ func (cancelUpdate *StateCancelUpdate) OnEnter() {
 	cancelUpdate.App.EmitAppEvent(cancelUpdate.Name)
 
 	cancelUpdate.TargetSlotIndex = 0
+	// all slots updated if none left on current version
+	cancelUpdate.CurrentSlotIndex = len(cancelUpdate.App.GetSlots()) - 1
 	for index, slot := range cancelUpdate.App.GetSlots() {
 		if slot.Version.ID == cancelUpdate.App.CurrentVersion.ID {
 			cancelUpdate.CurrentSlotIndex = index - 1
@@ -55,6 +57,7 @@ func (cancelUpdate *StateCancelUpdate) Step() {
 		logrus.Infof("archive current task")
 		cancelUpdate.CurrentSlot.Archive()
 		cancelUpdate.CurrentSlot.DispatchNewTask(cancelUpdate.App.CurrentVersion)
+		cancelUpdate.CurrentSlot.StartRestartPolicy()
 
 		// when slot get running and pass health check
 	} else if cancelUpdate.CurrentSlot.StateIs(SLOT_STATE_TASK_RUNNING) &&
@@ -73,6 +76,7 @@ func (cancelUpdate *StateCancelUpdate) Step() {
 		logrus.Infof("archive current task")
 		cancelUpdate.CurrentSlot.Archive()
 		cancelUpdate.CurrentSlot.DispatchNewTask(cancelUpdate.App.CurrentVersion)
+		cancelUpdate.CurrentSlot.StartRestartPolicy()
 
 		// when last slot got restarted
 	} else if cancelUpdate.CurrentSlot.StateIs(SLOT_STATE_TASK_RUNNING) &&
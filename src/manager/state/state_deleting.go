@@ -30,7 +30,14 @@ func (deleting *StateDeleting) OnEnter() {
 
 	deleting.App.EmitAppEvent(deleting.Name)
 
-	deleting.CurrentSlotIndex = len(deleting.App.GetSlots()) - 1
+	// indices may have holes when deleted in the middle of blue green update,
+	// where old slots at temporary indices are reaped in any order
+	deleting.CurrentSlotIndex = -1
+	for _, slot := range deleting.App.GetSlots() {
+		if slot.Index > deleting.CurrentSlotIndex {
+			deleting.CurrentSlotIndex = slot.Index
+		}
+	}
 	deleting.TargetSlotIndex = 0
 
 	deleting.CurrentSlot, _ = deleting.App.GetSlot(deleting.CurrentSlotIndex)
@@ -79,6 +86,10 @@ func (deleting *StateDeleting) Step() {
 }
 
 func (deleting *StateDeleting) SlotSafeToRemoveFromApp(slot *Slot) bool {
+	if slot == nil {
+		return true
+	}
+
 	return slot.StateIs(SLOT_STATE_REAP) || slot.Abnormal()
 }
 
@@ -0,0 +1,104 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// tasks of slots between CurrentSlotIndex and TargetSlotIndex are killed and
+// launched again with current version one by one, the next slot is restarted
+// only after the previous one is running and healthy
+type StateRestarting struct {
+	Name string
+	App  *App
+
+	CurrentSlot      *Slot
+	CurrentSlotIndex int
+	TargetSlotIndex  int
+	lock             sync.Mutex
+}
+
+func NewStateRestarting(app *App, from, to int) *StateRestarting {
+	return &StateRestarting{
+		App:              app,
+		Name:             APP_STATE_RESTARTING,
+		CurrentSlotIndex: from,
+		TargetSlotIndex:  to,
+	}
+}
+
+func (restarting *StateRestarting) OnEnter() {
+	logrus.Debug("state restarting OnEnter")
+
+	restarting.App.EmitAppEvent(restarting.Name)
+
+	restarting.CurrentSlot, _ = restarting.App.GetSlot(restarting.CurrentSlotIndex)
+	if restarting.CurrentSlot != nil {
+		restarting.kill(restarting.CurrentSlot)
+	}
+}
+
+func (restarting *StateRestarting) OnExit() {
+	logrus.Debug("state restarting OnExit")
+}
+
+func (restarting *StateRestarting) Step() {
+	logrus.Debug("state restarting step")
+
+	if restarting.CurrentSlot == nil {
+		restarting.App.TransitTo(APP_STATE_NORMAL)
+		return
+	}
+
+	if restarting.CurrentSlot.StateIs(SLOT_STATE_REAP) || restarting.CurrentSlot.Abnormal() {
+		logrus.Infof("restarting slot %s", restarting.CurrentSlot.ID)
+
+		restarting.CurrentSlot.Archive()
+		restarting.CurrentSlot.DispatchNewTask(restarting.App.CurrentVersion)
+
+	} else if restarting.CurrentSlot.StateIs(SLOT_STATE_TASK_RUNNING) &&
+		restarting.CurrentSlot.Healthy() &&
+		restarting.CurrentSlotIndex < restarting.TargetSlotIndex {
+
+		restarting.lock.Lock()
+
+		restarting.CurrentSlot.StartRestartPolicy()
+
+		restarting.CurrentSlotIndex += 1
+		restarting.CurrentSlot, _ = restarting.App.GetSlot(restarting.CurrentSlotIndex)
+		restarting.kill(restarting.CurrentSlot)
+
+		restarting.lock.Unlock()
+
+	} else if restarting.CurrentSlot.StateIs(SLOT_STATE_TASK_RUNNING) &&
+		restarting.CurrentSlot.Healthy() &&
+		restarting.CurrentSlotIndex == restarting.TargetSlotIndex {
+
+		logrus.Debug("state restarting step, all slots restarted")
+
+		restarting.CurrentSlot.StartRestartPolicy()
+		restarting.App.TransitTo(APP_STATE_NORMAL)
+	} else {
+		logrus.Info("state restarting step, do nothing")
+	}
+}
+
+// slot waiting for offer is dispatched again once reaped, don't leave it in queue
+func (restarting *StateRestarting) kill(slot *Slot) {
+	if slot.StateIs(SLOT_STATE_PENDING_OFFER) {
+		OfferAllocatorInstance().RemoveSlotFromPendingOfferQueue(slot)
+	}
+
+	slot.KillTask()
+}
+
+func (restarting *StateRestarting) StateName() string {
+	return restarting.Name
+}
+
+func (restarting *StateRestarting) CanTransitTo(targetState string) bool {
+	logrus.Debugf("state restarting CanTransitTo %s", targetState)
+
+	return targetState == APP_STATE_NORMAL || targetState == APP_STATE_DELETING
+}
@@ -4,6 +4,8 @@ import (
 	"sync"
 
 	"github.com/Sirupsen/logrus"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
 )
 
 type StateScaleDown struct {
@@ -13,13 +15,16 @@ type StateScaleDown struct {
 	CurrentSlot      *Slot
 	CurrentSlotIndex int
 	TargetSlotIndex  int
-	lock             sync.Mutex
+	// slots chosen to be removed, highest slots are removed one by one if empty
+	Indices []int
+	lock    sync.Mutex
 }
 
-func NewStateScaleDown(app *App) *StateScaleDown {
+func NewStateScaleDown(app *App, indices []int) *StateScaleDown {
 	return &StateScaleDown{
-		App:  app,
-		Name: APP_STATE_SCALE_DOWN,
+		App:     app,
+		Name:    APP_STATE_SCALE_DOWN,
+		Indices: indices,
 	}
 }
 
@@ -28,6 +33,19 @@ func (scaleDown *StateScaleDown) OnEnter() {
 
 	scaleDown.App.EmitAppEvent(scaleDown.Name)
 
+	if len(scaleDown.Indices) > 0 {
+		for _, index := range scaleDown.Indices {
+			if slot, found := scaleDown.App.GetSlot(index); found {
+				if slot.StateIs(SLOT_STATE_PENDING_OFFER) {
+					OfferAllocatorInstance().RemoveSlotFromPendingOfferQueue(slot)
+				}
+				slot.DrainAndKillTask()
+			}
+		}
+
+		return
+	}
+
 	scaleDown.CurrentSlotIndex = len(scaleDown.App.GetSlots()) - 1
 
 	if scaleDown.App.IsFixed() {
@@ -37,7 +55,7 @@ func (scaleDown *StateScaleDown) OnEnter() {
 
 	scaleDown.CurrentSlot, _ = scaleDown.App.GetSlot(scaleDown.CurrentSlotIndex)
 	if scaleDown.CurrentSlot != nil {
-		scaleDown.CurrentSlot.KillTask()
+		scaleDown.CurrentSlot.DrainAndKillTask()
 	}
 }
 
@@ -48,6 +66,11 @@ func (scaleDown *StateScaleDown) OnExit() {
 func (scaleDown *StateScaleDown) Step() {
 	logrus.Debug("state scaleDown step")
 
+	if len(scaleDown.Indices) > 0 {
+		scaleDown.stepIndices()
+		return
+	}
+
 	if scaleDown.SlotSafeToRemoveFromApp(scaleDown.CurrentSlot) && scaleDown.CurrentSlotIndex == scaleDown.TargetSlotIndex {
 		scaleDown.App.RemoveSlot(scaleDown.CurrentSlotIndex)
 		scaleDown.App.TransitTo(APP_STATE_NORMAL)
@@ -60,7 +83,7 @@ func (scaleDown *StateScaleDown) Step() {
 			scaleDown.App.CurrentVersion.IP = scaleDown.App.CurrentVersion.IP[:scaleDown.CurrentSlotIndex]
 		}
 		scaleDown.CurrentSlot, _ = scaleDown.App.GetSlot(scaleDown.CurrentSlotIndex)
-		scaleDown.CurrentSlot.KillTask()
+		scaleDown.CurrentSlot.DrainAndKillTask()
 
 		scaleDown.lock.Unlock()
 	} else {
@@ -68,7 +91,116 @@ func (scaleDown *StateScaleDown) Step() {
 	}
 }
 
+// remove chosen slots once all of them are reaped, slots at the top are then
+// moved into the holes so that indices stay contiguous
+func (scaleDown *StateScaleDown) stepIndices() {
+	scaleDown.lock.Lock()
+	defer scaleDown.lock.Unlock()
+
+	if !scaleDown.App.StateIs(APP_STATE_SCALE_DOWN) {
+		return
+	}
+
+	if !slotsReaped(scaleDown.App.GetSlots(), scaleDown.Indices) {
+		logrus.Info("state scaleDown step, waiting chosen slots to be reaped")
+		return
+	}
+
+	for _, index := range scaleDown.Indices {
+		scaleDown.App.RemoveSlot(index)
+	}
+
+	scaleDown.compactSlots()
+
+	if scaleDown.App.IsFixed() {
+		ips := make([]string, 0)
+		for _, slot := range scaleDown.App.GetSlots() {
+			ips = append(ips, slot.Ip)
+		}
+		scaleDown.App.CurrentVersion.IP = ips
+	}
+
+	scaleDown.App.TransitTo(APP_STATE_NORMAL)
+}
+
+// as few slots as possible are renamed, dns records and gateway targets of
+// the moved ones are withdrawn under old indices and published under new ones
+func (scaleDown *StateScaleDown) compactSlots() {
+	indices := compactIndices(scaleDown.App.GetSlots())
+	if len(indices) == 0 {
+		return
+	}
+
+	moved := make([]*Slot, 0)
+	for _, slot := range scaleDown.App.GetSlots() {
+		if _, found := indices[slot]; found {
+			moved = append(moved, slot)
+		}
+	}
+
+	for _, slot := range moved {
+		logrus.Infof("moving slot %s to index %d", slot.ID, indices[slot])
+		if slot.Healthy() {
+			slot.EmitTaskEvent(eventbus.EventTypeTaskUnhealthy)
+		}
+	}
+
+	scaleDown.App.reindexSlots(indices)
+
+	for _, slot := range moved {
+		if slot.Healthy() {
+			slot.EmitTaskEvent(eventbus.EventTypeTaskHealthy)
+		}
+		slot.Touch()
+	}
+}
+
+// whether chosen slots are all reaped, the ones already removed included
+func slotsReaped(slots []*Slot, indices []int) bool {
+	chosen := make(map[int]bool)
+	for _, index := range indices {
+		chosen[index] = true
+	}
+
+	for _, slot := range slots {
+		if chosen[slot.Index] && !slotSafeToRemove(slot) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// new indices of slots left beyond the count of them, which are moved into
+// the holes in order
+func compactIndices(slots []*Slot) map[*Slot]int {
+	exists := make(map[int]bool)
+	for _, slot := range slots {
+		exists[slot.Index] = true
+	}
+
+	holes := make([]int, 0)
+	for i := 0; i < len(slots); i++ {
+		if !exists[i] {
+			holes = append(holes, i)
+		}
+	}
+
+	indices := make(map[*Slot]int)
+	for _, slot := range slots {
+		if slot.Index >= len(slots) {
+			indices[slot] = holes[len(indices)]
+		}
+	}
+
+	return indices
+}
+
 func (scaleDown *StateScaleDown) SlotSafeToRemoveFromApp(slot *Slot) bool {
+	return slotSafeToRemove(slot)
+}
+
+func slotSafeToRemove(slot *Slot) bool {
 	return slot.StateIs(SLOT_STATE_REAP) || slot.Abnormal()
 }
 
@@ -0,0 +1,124 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSlots(t *testing.T) {
+	slots := []*Slot{
+		{Index: 0, Ip: "192.168.1.10"},
+		{Index: 1, Ip: "192.168.1.11"},
+		{Index: 2, Ip: "192.168.1.12"},
+	}
+
+	chosen, err := resolveSlots(slots, []int{1, 2, 1}, []string{"192.168.1.11", "192.168.1.10"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 0}, chosen)
+
+	_, err = resolveSlots(slots, []int{3}, nil)
+	assert.NotNil(t, err)
+
+	_, err = resolveSlots(slots, nil, []string{"192.168.1.13"})
+	assert.NotNil(t, err)
+}
+
+func TestSlotsReaped(t *testing.T) {
+	slots := []*Slot{
+		{Index: 0, State: SLOT_STATE_TASK_RUNNING},
+		{Index: 1, State: SLOT_STATE_REAP},
+		{Index: 3, State: SLOT_STATE_TASK_KILLED},
+	}
+
+	assert.True(t, slotsReaped(slots, []int{1, 3}))
+	// removed already
+	assert.True(t, slotsReaped(slots, []int{1, 2}))
+	assert.False(t, slotsReaped(slots, []int{0, 1}))
+}
+
+func TestCompactIndices(t *testing.T) {
+	// 1 and 3 of 6 slots removed
+	slots := []*Slot{{Index: 0}, {Index: 2}, {Index: 4}, {Index: 5}}
+
+	indices := compactIndices(slots)
+	assert.Len(t, indices, 2)
+	assert.Equal(t, 1, indices[slots[2]])
+	assert.Equal(t, 3, indices[slots[3]])
+
+	// top slots removed, nothing to move
+	assert.Empty(t, compactIndices([]*Slot{{Index: 0}, {Index: 1}}))
+}
+
+// collects task health events of an app
+type healthListener struct {
+	appID  string
+	events chan *eventbus.Event
+}
+
+func (l *healthListener) Write(e *eventbus.Event) error {
+	l.events <- e
+	return nil
+}
+
+func (l *healthListener) InterestIn(e *eventbus.Event) bool {
+	return e.AppID == l.appID &&
+		(e.Type == eventbus.EventTypeTaskHealthy || e.Type == eventbus.EventTypeTaskUnhealthy)
+}
+
+func (l *healthListener) Key() string {
+	return "health-" + l.appID
+}
+
+func (l *healthListener) Wait() {}
+
+func (l *healthListener) next(t *testing.T) *types.TaskInfoEvent {
+	select {
+	case e := <-l.events:
+		payload := e.Payload.(*types.TaskInfoEvent)
+		payload.State = e.Type
+		return payload
+	case <-time.After(time.Second):
+		t.Fatal("no task health event")
+		return nil
+	}
+}
+
+func TestScaleDownSlotsRepublishMoved(t *testing.T) {
+	eventbus.Init()
+	listener := &healthListener{appID: "moved-test-", events: make(chan *eventbus.Event, 16)}
+	eventbus.AddListener(listener)
+	defer eventbus.RemoveListener(listener)
+
+	version := testVersion(60)
+	version.AppName = "moved"
+	version.Instances = 3
+	app := newTestApp(t, version)
+	assert.Equal(t, listener.appID, app.ID)
+
+	// published by creating
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, eventbus.EventTypeTaskHealthy, listener.next(t).State)
+	}
+
+	assert.Nil(t, app.ScaleDownSlots(0, []int{0}, nil))
+
+	slot, _ := app.GetSlot(0)
+	moved, _ := app.GetSlot(2)
+	slot.SetState(SLOT_STATE_TASK_KILLED)
+	assert.True(t, app.StateIs(APP_STATE_NORMAL))
+	assert.Equal(t, 0, moved.Index)
+
+	// withdrawn under the old index, published under the new one
+	e := listener.next(t)
+	assert.Equal(t, eventbus.EventTypeTaskUnhealthy, e.State)
+	assert.Equal(t, 2, e.SlotIndex)
+	e = listener.next(t)
+	assert.Equal(t, eventbus.EventTypeTaskHealthy, e.State)
+	assert.Equal(t, 0, e.SlotIndex)
+	assert.Equal(t, moved.ID, e.TaskID)
+}
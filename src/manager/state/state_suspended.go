@@ -0,0 +1,52 @@
+package state
+
+import (
+	"github.com/Sirupsen/logrus"
+)
+
+// all tasks of the app are killed, slots are kept so that the app can be
+// resumed with the same versions, ips and weights
+type StateSuspended struct {
+	Name string
+	App  *App
+}
+
+func NewStateSuspended(app *App) *StateSuspended {
+	return &StateSuspended{
+		App:  app,
+		Name: APP_STATE_SUSPENDED,
+	}
+}
+
+func (suspended *StateSuspended) OnEnter() {
+	logrus.Debug("state suspended OnEnter")
+
+	suspended.App.EmitAppEvent(suspended.Name)
+
+	for _, slot := range suspended.App.GetSlots() {
+		if slot.StateIs(SLOT_STATE_PENDING_OFFER) {
+			OfferAllocatorInstance().RemoveSlotFromPendingOfferQueue(slot)
+		}
+
+		slot.KillTask()
+	}
+}
+
+func (suspended *StateSuspended) OnExit() {
+	logrus.Debug("state suspended OnExit")
+}
+
+func (suspended *StateSuspended) Step() {
+	logrus.Debug("state suspended step")
+}
+
+func (suspended *StateSuspended) StateName() string {
+	return suspended.Name
+}
+
+// suspended app can only be resumed or deleted
+func (suspended *StateSuspended) CanTransitTo(targetState string) bool {
+	logrus.Debugf("state suspended CanTransitTo %s", targetState)
+
+	return targetState == APP_STATE_NORMAL || targetState == APP_STATE_DELETING
+}
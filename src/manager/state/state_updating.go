@@ -2,17 +2,29 @@ package state
 
 import (
 	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
 	"github.com/Dataman-Cloud/swan/src/utils"
 )
 
+const (
+	UPDATE_POLICY_ACTION_ROLLBACK = "rollback"
+	UPDATE_POLICY_ACTION_PAUSE    = "pause"
+
+	UPDATE_STRATEGY_ROLLING    = "rolling"
+	UPDATE_STRATEGY_CANARY     = "canary"
+	UPDATE_STRATEGY_BLUE_GREEN = "blueGreen"
+)
+
 var ValidNextTransitionState = []string{
 	APP_STATE_CANCEL_UPDATE,
 	APP_STATE_DELETING,
 	APP_STATE_UPDATING,
 	APP_STATE_NORMAL,
+	APP_STATE_CANARY,
 }
 
 type StateUpdating struct {
@@ -24,13 +36,26 @@ type StateUpdating struct {
 	TargetSlotIndex     int
 	SlotCountNeedUpdate int
 	lock                sync.Mutex
+
+	// failed tasks of proposed version since update began
+	Failovers int
+	// automatic proceeding paused due to too many failovers
+	Paused bool
+	// surge slots take temporary indices starting from Offset,
+	// surge slot at Offset+i replaces slot i
+	Offset int
+
+	proceedTimer *time.Timer
+	// nested steps triggered by slot changes made in surgeStep are skipped
+	stepping bool
 }
 
-func NewStateUpdating(app *App, slotCountNeedUpdate int) *StateUpdating {
+func NewStateUpdating(app *App, slotCountNeedUpdate int, failovers int) *StateUpdating {
 	return &StateUpdating{
 		App:                 app,
 		Name:                APP_STATE_UPDATING,
 		SlotCountNeedUpdate: slotCountNeedUpdate,
+		Failovers:           failovers,
 	}
 }
 
@@ -39,6 +64,8 @@ func (updating *StateUpdating) OnEnter() {
 
 	updating.App.EmitAppEvent(updating.Name)
 
+	updating.relaunchFailedSlots()
+
 	updating.CurrentSlotIndex = -1
 	for index, slot := range updating.App.GetSlots() {
 		if slot.Version.ID == updating.App.ProposedVersion.ID {
@@ -51,36 +78,106 @@ func (updating *StateUpdating) OnEnter() {
 	}
 	updating.TargetSlotIndex = updating.CurrentSlotIndex + updating.SlotCountNeedUpdate - 1
 
+	if updating.surging() {
+		updating.Offset = len(updating.App.GetSlots())
+		updating.surgeStep()
+		return
+	}
+
 	updating.CurrentSlot, _ = updating.App.GetSlot(updating.CurrentSlotIndex)
 	// rolling update on the first slot
 
 	if updating.CurrentSlot != nil {
-		if updating.CurrentSlotIndex == 0 {
+		// canaries get no traffic until weight ramping begins
+		if updating.CurrentSlotIndex == 0 || updating.CurrentSlotIndex < canaryInstances(updating.App.ProposedVersion) {
 			updating.CurrentSlot.SetWeight(0)
 		}
-		updating.CurrentSlot.KillTask()
+		updating.CurrentSlot.DrainAndKillTask()
+	}
+}
+
+// slots of proposed version left failed by paused update are relaunched once
+// the operator proceeds
+func (updating *StateUpdating) relaunchFailedSlots() {
+	app := updating.App
+	for _, slot := range app.GetSlots() {
+		if slot.Version.ID != app.ProposedVersion.ID || !(slot.StateIs(SLOT_STATE_REAP) || slot.Abnormal()) {
+			continue
+		}
+
+		logrus.Infof("relaunch slot %s failed before update paused", slot.ID)
+		slot.Archive()
+		if app.IsFixed() {
+			slot.Ip = app.ProposedVersion.IP[slot.Index]
+		}
+		slot.DispatchNewTask(app.ProposedVersion)
+		slot.StartRestartPolicy()
 	}
 }
 
 func (updating *StateUpdating) OnExit() {
 	logrus.Debug("state updating OnExit")
+
+	updating.lock.Lock()
+	if updating.proceedTimer != nil {
+		updating.proceedTimer.Stop()
+	}
+	updating.lock.Unlock()
+
+	if updating.Offset > 0 {
+		updating.stepping = true
+		updating.discardSurgeSlots()
+	}
 }
 
 func (updating *StateUpdating) Step() {
 	logrus.Debug("state updating step")
 
+	// timer lost when state recovered from store
+	if updating.autoProceed() && updating.batchFinished() {
+		updating.lock.Lock()
+		lost := updating.proceedTimer == nil
+		updating.lock.Unlock()
+		if lost {
+			updating.scheduleNextBatch()
+		}
+		return
+	}
+
+	if updating.surging() {
+		updating.surgeStep()
+		return
+	}
+
 	if (updating.CurrentSlot.StateIs(SLOT_STATE_REAP) ||
 		updating.CurrentSlot.StateIs(SLOT_STATE_TASK_KILLED) ||
 		updating.CurrentSlot.StateIs(SLOT_STATE_TASK_FINISHED) ||
 		updating.CurrentSlot.Abnormal()) &&
 		updating.CurrentSlotIndex <= updating.TargetSlotIndex {
 
+		// relaunched by the update, or left for the operator, not by restart policy
+		updating.CurrentSlot.StopRestartPolicy()
+
+		// task of proposed version failed
+		if updating.CurrentSlot.Version.ID == updating.App.ProposedVersion.ID {
+			// left for the operator once paused
+			if updating.Paused {
+				return
+			}
+
+			updating.Failovers += 1
+			if updating.failoversExceeded() && updating.takeFailoverAction() {
+				return
+			}
+		}
+
 		logrus.Infof("archive current task")
 		updating.CurrentSlot.Archive()
 		if updating.App.IsFixed() {
 			updating.CurrentSlot.Ip = updating.App.ProposedVersion.IP[updating.CurrentSlotIndex]
 		}
 		updating.CurrentSlot.DispatchNewTask(updating.App.ProposedVersion)
+		updating.CurrentSlot.StartRestartPolicy()
 
 	} else if updating.CurrentSlot.StateIs(SLOT_STATE_TASK_RUNNING) &&
 		updating.CurrentSlot.Healthy() &&
@@ -88,27 +185,274 @@ func (updating *StateUpdating) Step() {
 
 		updating.CurrentSlotIndex += 1
 		updating.CurrentSlot, _ = updating.App.GetSlot(updating.CurrentSlotIndex)
-		updating.CurrentSlot.KillTask()
+		if updating.CurrentSlotIndex < canaryInstances(updating.App.ProposedVersion) {
+			updating.CurrentSlot.SetWeight(0)
+		}
+		updating.CurrentSlot.DrainAndKillTask()
 
 	} else if updating.CurrentSlot.StateIs(SLOT_STATE_TASK_RUNNING) &&
 		updating.CurrentSlot.Healthy() &&
 		updating.CurrentSlotIndex == updating.TargetSlotIndex {
 
-		if updating.CurrentSlotIndex == len(updating.App.GetSlots())-1 {
-			logrus.Debug("state updating step, updating done,  all slots updated")
+		updating.finishBatch()
+	} else {
+		logrus.Info("state updating step, do nothing")
+	}
+}
+
+// batch updated and waiting to proceed, neither the canaries nor the last one
+func (updating *StateUpdating) batchFinished() bool {
+	if updating.CurrentSlotIndex != updating.TargetSlotIndex ||
+		updating.TargetSlotIndex+1 == canaryInstances(updating.App.ProposedVersion) ||
+		updating.TargetSlotIndex >= len(updating.App.GetSlots())-1 {
+		return false
+	}
+
+	slot, found := updating.App.GetSlot(updating.TargetSlotIndex)
+	return found && slot.Version.ID == updating.App.ProposedVersion.ID &&
+		slot.StateIs(SLOT_STATE_TASK_RUNNING) && slot.Healthy()
+}
+
+// all slots of current step updated
+func (updating *StateUpdating) finishBatch() {
+	if updating.TargetSlotIndex+1 == canaryInstances(updating.App.ProposedVersion) {
+		logrus.Debug("state updating step, canaries updated")
+
+		updating.App.TransitTo(APP_STATE_CANARY)
+	} else if updating.CurrentSlotIndex == len(updating.App.GetSlots())-1 {
+		logrus.Debug("state updating step, updating done,  all slots updated")
+
+		updating.App.CurrentVersion = updating.App.ProposedVersion
+		updating.App.ProposedVersion = nil
+
+		updating.App.TransitTo(APP_STATE_NORMAL)
+	} else {
+		logrus.Debug("state updating step, updating done,  not all slots updated")
+
+		if updating.autoProceed() {
+			updating.scheduleNextBatch()
+		}
+	}
+}
 
-			updating.App.CurrentVersion = updating.App.ProposedVersion
-			updating.App.ProposedVersion = nil
+// surge based update is used when maxSurge or maxUnavailable specified, except for canaries
+func (updating *StateUpdating) surging() bool {
+	policy := updating.App.ProposedVersion.UpdatePolicy
+	if policy == nil || (policy.MaxSurge <= 0 && policy.MaxUnavailable <= 0) {
+		return false
+	}
+
+	return updating.TargetSlotIndex+1 != canaryInstances(updating.App.ProposedVersion)
+}
+
+func (updating *StateUpdating) surgeStep() {
+	if updating.stepping {
+		return
+	}
+
+	updating.stepping = true
+	for updating.surgeOnce() {
+	}
+	updating.stepping = false
+}
 
-			updating.App.TransitTo(APP_STATE_NORMAL)
+// take one action within bounds of maxSurge and maxUnavailable,
+// return false when nothing can be done for now
+func (updating *StateUpdating) surgeOnce() bool {
+	app := updating.App
+	policy := app.ProposedVersion.UpdatePolicy
+
+	surges, unavailable := 0, 0
+	candidates := make([]*Slot, 0)
+	for i := updating.CurrentSlotIndex; i <= updating.TargetSlotIndex; i++ {
+		slot, found := app.GetSlot(i)
+		if !found {
+			continue
+		}
+
+		if surge, found := app.GetSlot(updating.Offset + i); found {
+			surges += 1
+			if !surge.StateIs(SLOT_STATE_TASK_RUNNING) || !surge.Healthy() {
+				continue
+			}
+
+			// surge slot ready, the old one can go
+			if slot.StateIs(SLOT_STATE_REAP) || slot.Abnormal() {
+				updating.promoteSurgeSlot(surge, i)
+				return true
+			}
+
+			if !slot.StateIs(SLOT_STATE_PENDING_KILL) {
+				slot.DrainAndKillTask()
+				return true
+			}
+
+			continue
+		}
+
+		if slot.StateIs(SLOT_STATE_REAP) || slot.Abnormal() {
+			slot.StopRestartPolicy()
+
+			// task of proposed version failed
+			if slot.Version.ID == app.ProposedVersion.ID {
+				// left for the operator once paused
+				if updating.Paused {
+					unavailable += 1
+					continue
+				}
+
+				updating.Failovers += 1
+				if updating.failoversExceeded() && updating.takeFailoverAction() {
+					return false
+				}
+			}
+
+			slot.Archive()
+			if app.IsFixed() {
+				slot.Ip = app.ProposedVersion.IP[i]
+			}
+			slot.DispatchNewTask(app.ProposedVersion)
+			slot.StartRestartPolicy()
+			return true
+		}
+
+		if !slot.StateIs(SLOT_STATE_TASK_RUNNING) || !slot.Healthy() {
+			unavailable += 1
+		} else if slot.Version.ID != app.ProposedVersion.ID {
+			candidates = append(candidates, slot)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if surges == 0 && unavailable == 0 {
+			logrus.Debug("state updating step, all slots of current step updated")
+
+			updating.CurrentSlotIndex = updating.TargetSlotIndex
+			updating.finishBatch()
+		}
+
+		return false
+	}
+
+	slot := candidates[0]
+	if surges < int(policy.MaxSurge) {
+		logrus.Infof("launch surge slot for slot %s", slot.ID)
+
+		surge := NewSlot(app, app.ProposedVersion, updating.Offset+slot.Index)
+		app.SetSlot(surge.Index, surge)
+		surge.DispatchNewTask(app.ProposedVersion)
+		return true
+	}
+
+	if unavailable < int(policy.MaxUnavailable) {
+		logrus.Infof("kill slot %s to update in place", slot.ID)
+
+		slot.DrainAndKillTask()
+		return true
+	}
+
+	return false
+}
+
+// surge slot takes over index of the slot it replaced
+func (updating *StateUpdating) promoteSurgeSlot(surge *Slot, index int) {
+	logrus.Infof("surge slot %s takes over slot %d", surge.ID, index)
+
+	updating.App.RemoveSlot(index)
+
+	// gateway and dns know the slot by its temporary id
+	if surge.Healthy() {
+		surge.EmitTaskEvent(eventbus.EventTypeTaskUnhealthy)
+	}
+
+	updating.App.reindexSlots(map[*Slot]int{surge: index})
+
+	if surge.Healthy() {
+		surge.EmitTaskEvent(eventbus.EventTypeTaskHealthy)
+	}
+}
+
+// surge slots must not be left at temporary indices when update interrupted,
+// those whose old slot already gone are promoted, the others are discarded
+func (updating *StateUpdating) discardSurgeSlots() {
+	for _, surge := range updating.App.GetSlots() {
+		if surge.Index < updating.Offset {
+			continue
+		}
+
+		index := surge.Index - updating.Offset
+		if slot, found := updating.App.GetSlot(index); found && slot.Dispatched() {
+			surge.KillTask()
+			updating.App.RemoveSlot(surge.Index)
 		} else {
-			logrus.Debug("state updating step, updating done,  not all slots updated")
+			updating.promoteSurgeSlot(surge, index)
 		}
-	} else {
-		logrus.Info("state updating step, do nothing")
 	}
 }
 
+// rolling update proceeds by itself when batchSize of update policy specified
+func (updating *StateUpdating) autoProceed() bool {
+	policy := updating.App.ProposedVersion.UpdatePolicy
+	return policy != nil && policy.BatchSize > 0 && !updating.Paused
+}
+
+func (updating *StateUpdating) failoversExceeded() bool {
+	policy := updating.App.ProposedVersion.UpdatePolicy
+	return policy != nil && policy.MaxFailovers > 0 && updating.Failovers > int(policy.MaxFailovers)
+}
+
+// return true if the failed slot should not be relaunched, as the update is
+// rolled back or paused
+func (updating *StateUpdating) takeFailoverAction() bool {
+	logrus.Warnf("app %s got %d failovers during update, exceed max failovers",
+		updating.App.ID, updating.Failovers)
+
+	switch updating.App.ProposedVersion.UpdatePolicy.Action {
+	case UPDATE_POLICY_ACTION_ROLLBACK:
+		if err := updating.App.TransitTo(APP_STATE_CANCEL_UPDATE); err != nil {
+			logrus.Errorf("rollback app %s failed: %s", updating.App.ID, err.Error())
+			return false
+		}
+
+		return true
+	default:
+		// the failed slot is left for the operator to cancel the update, or
+		// proceed with it relaunched
+		logrus.Warnf("update of app %s paused", updating.App.ID)
+		updating.Paused = true
+		return true
+	}
+}
+
+func (updating *StateUpdating) scheduleNextBatch() {
+	updating.lock.Lock()
+	defer updating.lock.Unlock()
+
+	if updating.proceedTimer != nil {
+		return
+	}
+
+	policy := updating.App.ProposedVersion.UpdatePolicy
+	slotCountNeedUpdate := int(policy.BatchSize)
+	if remains := len(updating.App.GetSlots()) - updating.CurrentSlotIndex - 1; slotCountNeedUpdate > remains {
+		slotCountNeedUpdate = remains
+	}
+
+	logrus.Infof("app %s proceeding update of next %d slots in %d seconds",
+		updating.App.ID, slotCountNeedUpdate, policy.UpdateDelay)
+
+	updating.proceedTimer = time.AfterFunc(time.Duration(policy.UpdateDelay)*time.Second, func() {
+		// update canceled or proceeded by user in the meantime
+		if updating.App.StateMachine.CurrentState() != updating {
+			return
+		}
+
+		if err := updating.App.TransitTo(APP_STATE_UPDATING, slotCountNeedUpdate, updating.Failovers); err != nil {
+			logrus.Errorf("proceed update of app %s failed: %s", updating.App.ID, err.Error())
+		}
+	})
+}
+
 func (updating *StateUpdating) StateName() string {
 	return updating.Name
 }
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	eventbus "github.com/Dataman-Cloud/swan/src/event"
+	"github.com/Dataman-Cloud/swan/src/manager/connector"
+	"github.com/Dataman-Cloud/swan/src/manager/event"
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+// app created with store kept in memory and calls accepted by a fake mesos
+// master, tasks are driven by setting states of slots
+func newTestApp(t *testing.T, version *types.Version) *App {
+	eventbus.Init()
+	go eventbus.Start(context.Background())
+
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	store.InitMemoryStore()
+	connector.Init("root", &url.URL{})
+	connector.Instance().MesosLeaderHttpClient = connector.NewHTTPClient(strings.TrimPrefix(master.URL, "http://"), "/api/v1/scheduler")
+
+	app, err := NewApp(version, make(chan *event.UserEvent, 16))
+	assert.Nil(t, err)
+
+	for i := 0; i < int(version.Instances); i++ {
+		slot, _ := app.GetSlot(i)
+		runSlot(slot)
+	}
+	assert.True(t, app.StateIs(APP_STATE_NORMAL))
+
+	// version ids are in seconds, don't let the proposed one collide
+	app.CurrentVersion.ID = "1"
+
+	return app
+}
+
+func runSlot(slot *Slot) {
+	slot.SetState(SLOT_STATE_TASK_RUNNING)
+	slot.SetHealthy(true)
+}
+
+func testVersion(backoffSeconds float64) *types.Version {
+	return &types.Version{
+		AppName:        "web",
+		RunAs:          "test",
+		Instances:      1,
+		CPUs:           0.1,
+		Mem:            16,
+		BackoffSeconds: backoffSeconds,
+		Container: &types.Container{
+			Docker: &types.Docker{Image: "nginx", Network: "bridge"},
+		},
+	}
+}
+
+func waitFor(cond func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestRestartPolicyAfterUpdate(t *testing.T) {
+	app := newTestApp(t, testVersion(60))
+
+	proposed := testVersion(0.01)
+	proposed.AppVersion = "v2"
+	assert.Nil(t, app.Update(proposed))
+	assert.True(t, app.StateIs(APP_STATE_UPDATING))
+
+	slot, _ := app.GetSlot(0)
+	slot.SetState(SLOT_STATE_TASK_KILLED)
+	assert.Equal(t, "v2", slot.Version.AppVersion)
+	runSlot(slot)
+	assert.True(t, app.StateIs(APP_STATE_NORMAL))
+
+	task := slot.CurrentTask
+	slot.SetState(SLOT_STATE_TASK_FAILED)
+	assert.True(t, waitFor(func() bool { return slot.CurrentTask != task }))
+}
+
+func TestProceedTimerRearmed(t *testing.T) {
+	version := testVersion(60)
+	version.Instances = 2
+	app := newTestApp(t, version)
+
+	proposed := testVersion(60)
+	proposed.Instances = 2
+	proposed.AppVersion = "v2"
+	proposed.UpdatePolicy = &types.UpdatePolicy{BatchSize: 1, UpdateDelay: 60}
+	assert.Nil(t, app.Update(proposed))
+	assert.True(t, app.StateIs(APP_STATE_UPDATING))
+
+	slot, _ := app.GetSlot(0)
+	slot.SetState(SLOT_STATE_TASK_KILLED)
+	runSlot(slot)
+
+	updating := app.StateMachine.CurrentState().(*StateUpdating)
+	assert.NotNil(t, updating.proceedTimer)
+
+	// lost by failover
+	updating.proceedTimer.Stop()
+	updating.proceedTimer = nil
+
+	other, _ := app.GetSlot(1)
+	other.SetHealthy(true)
+	assert.NotNil(t, updating.proceedTimer)
+	updating.proceedTimer.Stop()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Dataman-Cloud/swan/src/mesosproto/mesos"
 	"github.com/Dataman-Cloud/swan/src/types"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/golang/protobuf/proto"
 )
 
@@ -254,45 +255,43 @@ func (builder *TaskBuilder) SetNetwork(network string, portsAvailable []uint64)
 func (builder *TaskBuilder) SetHealthCheck(healthCheck *types.HealthCheck) *TaskBuilder {
 	protocol := strings.ToLower(healthCheck.Protocol)
 	if protocol == "cmd" {
-		builder.taskInfo.HealthCheck = &mesos.HealthCheck{
-			Type: mesos.HealthCheck_COMMAND.Enum(),
-			Command: &mesos.CommandInfo{
-				Value: &healthCheck.Value,
-			},
-		}
+		builder.taskInfo.HealthCheck = commandHealthCheck(healthCheck.Value)
 	} else {
-		var namespacePort int32
-		for _, portMapping := range builder.task.Slot.Version.Container.Docker.PortMappings {
-			if portMapping.Name == healthCheck.PortName {
-				if strings.ToLower(builder.task.Slot.Version.Container.Docker.Network) == "host" {
-					namespacePort = portMapping.HostPort
-				} else if strings.ToLower(builder.task.Slot.Version.Container.Docker.Network) == "bridge" {
-					namespacePort = portMapping.ContainerPort
-				} else { // not support, shortcut
-					return builder
-				}
-			}
+		port, err := builder.healthCheckPort(healthCheck)
+		if err != nil {
+			logrus.Errorf("skip health check for slot %s: %s", builder.task.Slot.ID, err.Error())
+			return builder
 		}
 
-		if protocol == "http" {
-			builder.taskInfo.HealthCheck = &mesos.HealthCheck{
-				Type: mesos.HealthCheck_HTTP.Enum(),
-				Http: &mesos.HealthCheck_HTTPCheckInfo{
-					Scheme:   proto.String(protocol),
-					Port:     proto.Uint32(uint32(namespacePort)),
-					Path:     &healthCheck.Path,
-					Statuses: []uint32{uint32(200), uint32(201), uint32(301), uint32(302)},
-				},
+		switch protocol {
+		case "http", "https":
+			// statuses are left to custom executors by mesos, and headers are
+			// not supported at all, so perform the request by curl ourselves
+			if len(healthCheck.Statuses) > 0 || len(healthCheck.Headers) > 0 {
+				builder.taskInfo.HealthCheck = commandHealthCheck(httpCheckCommand(protocol, port, healthCheck))
+			} else {
+				builder.taskInfo.HealthCheck = &mesos.HealthCheck{
+					Type: mesos.HealthCheck_HTTP.Enum(),
+					Http: &mesos.HealthCheck_HTTPCheckInfo{
+						Scheme:   proto.String(protocol),
+						Port:     proto.Uint32(port),
+						Path:     &healthCheck.Path,
+						Statuses: DEFAULT_HEALTH_CHECK_STATUSES,
+					},
+				}
 			}
-		}
-
-		if protocol == "tcp" {
+		case "tcp":
 			builder.taskInfo.HealthCheck = &mesos.HealthCheck{
 				Type: mesos.HealthCheck_TCP.Enum(),
 				Tcp: &mesos.HealthCheck_TCPCheckInfo{
-					Port: proto.Uint32(uint32(namespacePort)),
+					Port: proto.Uint32(port),
 				},
 			}
+		case "grpc":
+			builder.taskInfo.HealthCheck = commandHealthCheck(grpcCheckCommand(port, healthCheck))
+		default:
+			logrus.Errorf("skip health check for slot %s: unknown protocol %s", builder.task.Slot.ID, healthCheck.Protocol)
+			return builder
 		}
 	}
 
@@ -0,0 +1,29 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dataman-Cloud/swan/src/manager/store"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneTaskHistory(t *testing.T) {
+	now := time.Now()
+
+	history := make([]*Task, 0)
+	for i := 0; i < store.TASK_HISTORY_LIMIT+10; i++ {
+		history = append(history, &Task{ArchivedAt: now.Add(-time.Duration(store.TASK_HISTORY_LIMIT+10-i) * time.Minute)})
+	}
+
+	pruned := pruneTaskHistory(history, now)
+	assert.Len(t, pruned, store.TASK_HISTORY_LIMIT)
+	assert.Equal(t, history[len(history)-1], pruned[len(pruned)-1])
+
+	history = []*Task{
+		{ArchivedAt: now.Add(-store.TASK_HISTORY_MAX_AGE - time.Hour)},
+		{ArchivedAt: now.Add(-time.Hour)},
+	}
+	assert.Equal(t, history[1:], pruneTaskHistory(history, now))
+}
@@ -0,0 +1,175 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+)
+
+// fields which don't change tasks launched from the version
+var fieldsWithoutRestart = []string{
+	"id",
+	"appVersion",
+	"instances",
+	"ip",
+	"killPolicy",
+	"updatPolicy",
+	"gateway",
+	"backoffSeconds",
+	"backoffFactor",
+	"maxLaunchDelaySeconds",
+	"maxRestarts",
+	"restartPolicy",
+	"readinessCheck",
+	"preStop",
+	"nonPreemptible",
+	"healthCheck.killAfterUnhealthySeconds",
+}
+
+// preview what an update with version would change, nothing of the app is modified
+func (app *App) PreviewUpdate(version *types.Version) *types.UpdatePreview {
+	preview := &types.UpdatePreview{
+		Errors:  make([]string, 0),
+		Changes: make([]*types.FieldChange, 0),
+		Slots:   make([]*types.SlotPreview, 0),
+	}
+
+	if !app.StateMachine.CanTransitTo(APP_STATE_UPDATING) || app.ProposedVersion != nil {
+		preview.Errors = append(preview.Errors, fmt.Sprintf("state machine can not transit from state: %s to state: %s",
+			app.StateMachine.ReadableState(), APP_STATE_UPDATING))
+	}
+
+	// validation formats the version, work on a copy
+	proposed := *version
+	if err := validateAndFormatVersion(&proposed); err != nil {
+		preview.Errors = append(preview.Errors, err.Error())
+	}
+
+	if app.CurrentVersion == nil {
+		preview.Errors = append(preview.Errors, "current version was losted")
+		return preview
+	}
+
+	if proposed.AppVersion != "" && proposed.AppVersion == app.CurrentVersion.AppVersion {
+		preview.Errors = append(preview.Errors, fmt.Sprintf("app version %s exists, choose another one", proposed.AppVersion))
+	}
+
+	// checking proposed version relies on container spec
+	if proposed.Container != nil && proposed.Container.Docker != nil {
+		if err := app.checkProposedVersionValid(&proposed); err != nil {
+			preview.Errors = append(preview.Errors, err.Error())
+		}
+	}
+
+	preview.Changes = diffVersions(app.CurrentVersion, &proposed)
+
+	for _, slot := range app.GetSlots() {
+		slotPreview := &types.SlotPreview{
+			Index:     slot.Index,
+			ID:        slot.ID,
+			VersionID: slot.Version.ID,
+			Reasons:   make([]string, 0),
+		}
+
+		for _, change := range diffVersions(slot.Version, &proposed) {
+			if !fieldNeedRestart(change.Field) {
+				continue
+			}
+
+			slotPreview.Reasons = append(slotPreview.Reasons, change.Field)
+		}
+
+		if app.IsFixed() && slot.Index < len(proposed.IP) && proposed.IP[slot.Index] != slot.Ip {
+			slotPreview.Reasons = append(slotPreview.Reasons, fmt.Sprintf("ip[%d]", slot.Index))
+		}
+
+		slotPreview.NeedRestart = len(slotPreview.Reasons) > 0
+		preview.Slots = append(preview.Slots, slotPreview)
+	}
+
+	preview.Valid = len(preview.Errors) == 0
+
+	return preview
+}
+
+func fieldNeedRestart(field string) bool {
+	for _, f := range fieldsWithoutRestart {
+		if field == f || strings.HasPrefix(field, f+".") || strings.HasPrefix(field, f+"[") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compare json representations of two versions field by field
+func diffVersions(from, to *types.Version) []*types.FieldChange {
+	changes := make([]*types.FieldChange, 0)
+	diffValues("", toGeneric(from), toGeneric(to), &changes)
+
+	return changes
+}
+
+func toGeneric(version *types.Version) interface{} {
+	var generic interface{}
+
+	data, _ := json.Marshal(version)
+	json.Unmarshal(data, &generic)
+
+	return generic
+}
+
+func diffValues(path string, from, to interface{}, changes *[]*types.FieldChange) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make([]string, 0)
+		for k := range fromMap {
+			keys = append(keys, k)
+		}
+		for k := range toMap {
+			if _, found := fromMap[k]; !found {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			field := k
+			if path != "" {
+				field = path + "." + k
+			}
+
+			// version id is always regenerated on update
+			if field == "id" {
+				continue
+			}
+
+			diffValues(field, fromMap[k], toMap[k], changes)
+		}
+
+		return
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice && len(fromSlice) == len(toSlice) {
+		for i := range fromSlice {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), fromSlice[i], toSlice[i], changes)
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, &types.FieldChange{
+			Field: path,
+			Old:   from,
+			New:   to,
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/Dataman-Cloud/swan/src/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffVersions(t *testing.T) {
+	from := &types.Version{
+		ID:        "1",
+		AppName:   "nginx",
+		Instances: 2,
+		Container: &types.Container{Docker: &types.Docker{Image: "nginx:1.10"}},
+		Env:       map[string]string{"A": "a"},
+	}
+	to := &types.Version{
+		ID:        "2",
+		AppName:   "nginx",
+		Instances: 2,
+		Container: &types.Container{Docker: &types.Docker{Image: "nginx:1.11"}},
+		Env:       map[string]string{"A": "a", "B": "b"},
+	}
+
+	changes := diffVersions(from, to)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "container.docker.image", changes[0].Field)
+	assert.Equal(t, "nginx:1.10", changes[0].Old)
+	assert.Equal(t, "nginx:1.11", changes[0].New)
+	assert.Equal(t, "env.B", changes[1].Field)
+	assert.Nil(t, changes[1].Old)
+
+	assert.Len(t, diffVersions(from, from), 0)
+}
+
+func TestFieldNeedRestart(t *testing.T) {
+	assert.True(t, fieldNeedRestart("container.docker.image"))
+	assert.True(t, fieldNeedRestart("env.B"))
+	assert.False(t, fieldNeedRestart("updatPolicy.batchSize"))
+	assert.False(t, fieldNeedRestart("ip[0]"))
+	assert.False(t, fieldNeedRestart("instances"))
+	assert.False(t, fieldNeedRestart("readinessCheck.path"))
+	assert.False(t, fieldNeedRestart("preStop.drainSeconds"))
+	assert.False(t, fieldNeedRestart("nonPreemptible"))
+	assert.False(t, fieldNeedRestart("healthCheck.killAfterUnhealthySeconds"))
+}
@@ -1,5 +1,16 @@
 package store
 
+import (
+	"time"
+)
+
+const (
+	// archived tasks kept for each slot, the oldest ones are pruned first
+	TASK_HISTORY_LIMIT = 100
+	// archived tasks older than this are pruned regardless of the limit
+	TASK_HISTORY_MAX_AGE = 7 * 24 * time.Hour
+)
+
 func (zk *ZkStore) UpdateCurrentTask(appId, slotId string, task *Task) error {
 	appStore, found := zk.Storage.Apps[appId]
 	if !found {
@@ -22,6 +33,28 @@ func (zk *ZkStore) UpdateCurrentTask(appId, slotId string, task *Task) error {
 	return zk.Apply(op, true)
 }
 
+func (zk *ZkStore) AppendTaskHistory(appId, slotId string, task *Task) error {
+	appStore, found := zk.Storage.Apps[appId]
+	if !found {
+		return ErrAppNotFound
+	}
+
+	_, found = appStore.Slots[slotId]
+	if !found {
+		return ErrSlotNotFound
+	}
+
+	op := &AtomicOp{
+		Op:      OP_ADD,
+		Entity:  ENTITY_TASK_HISTORY,
+		Param1:  appId,
+		Param2:  slotId,
+		Payload: task,
+	}
+
+	return zk.Apply(op, true)
+}
+
 func (zk *ZkStore) ListTaskHistory(appId, slotId string) []*Task {
 	zk.mu.RLock()
 	defer zk.mu.RUnlock()
@@ -38,3 +71,17 @@ func (zk *ZkStore) ListTaskHistory(appId, slotId string) []*Task {
 
 	return slotStore.TaskHistory
 }
+
+// history is in archived order, the oldest first
+func PruneTaskHistory(history []*Task, now time.Time) []*Task {
+	if len(history) > TASK_HISTORY_LIMIT {
+		history = history[len(history)-TASK_HISTORY_LIMIT:]
+	}
+
+	expiredBefore := now.Add(-TASK_HISTORY_MAX_AGE).UnixNano()
+	for len(history) > 0 && history[0].ArchivedAt < expiredBefore {
+		history = history[1:]
+	}
+
+	return history
+}
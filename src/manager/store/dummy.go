@@ -84,6 +84,10 @@ func (dummy *DummyStore) UpdateCurrentTask(appId, slotId string, task *Task) err
 	logrus.Debug("ListVersions from DummyStore")
 	return nil
 }
+func (dummy *DummyStore) AppendTaskHistory(appId, slotId string, task *Task) error {
+	logrus.Debug("AppendTaskHistory from DummyStore")
+	return nil
+}
 func (dummy *DummyStore) ListTaskHistory(appId, slotId string) []*Task {
 	logrus.Debug("ListVersions from DummyStore")
 	return nil
@@ -113,6 +117,81 @@ func (dummy *DummyStore) ListOfferallocatorItems() []*OfferAllocatorItem {
 	return nil
 }
 
+func (dummy *DummyStore) CreateGroup(group *Group) error {
+	logrus.Debug("CreateGroup from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) UpdateGroup(group *Group) error {
+	logrus.Debug("UpdateGroup from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) GetGroup(groupId string) *Group {
+	logrus.Debug("GetGroup from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) ListGroups() []*Group {
+	logrus.Debug("ListGroups from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) DeleteGroup(groupId string) error {
+	logrus.Debug("DeleteGroup from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) CreateJob(job *Job) error {
+	logrus.Debug("CreateJob from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) UpdateJob(job *Job) error {
+	logrus.Debug("UpdateJob from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) GetJob(jobId string) *Job {
+	logrus.Debug("GetJob from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) ListJobs() []*Job {
+	logrus.Debug("ListJobs from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) DeleteJob(jobId string) error {
+	logrus.Debug("DeleteJob from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) CreateQuota(quota *Quota) error {
+	logrus.Debug("CreateQuota from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) UpdateQuota(quota *Quota) error {
+	logrus.Debug("UpdateQuota from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) GetQuota(runAs string) *Quota {
+	logrus.Debug("GetQuota from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) ListQuotas() []*Quota {
+	logrus.Debug("ListQuotas from DummyStore")
+	return nil
+}
+
+func (dummy *DummyStore) DeleteQuota(runAs string) error {
+	logrus.Debug("DeleteQuota from DummyStore")
+	return nil
+}
+
 func (dummy *DummyStore) Synchronize() error {
 	logrus.Debug("Synchronize from DummyStore")
 	return nil
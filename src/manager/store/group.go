@@ -0,0 +1,64 @@
+package store
+
+func (zk *ZkStore) CreateGroup(group *Group) error {
+	if zk.GetGroup(group.ID) != nil {
+		return ErrGroupAlreadyExists
+	}
+
+	op := &AtomicOp{
+		Op:      OP_ADD,
+		Entity:  ENTITY_GROUP,
+		Param1:  group.ID,
+		Payload: group,
+	}
+
+	return zk.Apply(op, true)
+}
+
+func (zk *ZkStore) UpdateGroup(group *Group) error {
+	if zk.GetGroup(group.ID) == nil {
+		return ErrGroupNotFound
+	}
+
+	op := &AtomicOp{
+		Op:      OP_UPDATE,
+		Entity:  ENTITY_GROUP,
+		Param1:  group.ID,
+		Payload: group,
+	}
+
+	return zk.Apply(op, true)
+}
+
+func (zk *ZkStore) GetGroup(groupId string) *Group {
+	zk.mu.RLock()
+	defer zk.mu.RUnlock()
+
+	return zk.Storage.Groups[groupId]
+}
+
+func (zk *ZkStore) ListGroups() []*Group {
+	zk.mu.RLock()
+	defer zk.mu.RUnlock()
+
+	groups := make([]*Group, 0)
+	for _, group := range zk.Storage.Groups {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+func (zk *ZkStore) DeleteGroup(groupId string) error {
+	if zk.GetGroup(groupId) == nil {
+		return ErrGroupNotFound
+	}
+
+	op := &AtomicOp{
+		Op:     OP_REMOVE,
+		Entity: ENTITY_GROUP,
+		Param1: groupId,
+	}
+
+	return zk.Apply(op, true)
+}
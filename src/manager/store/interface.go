@@ -22,6 +22,7 @@ type Store interface {
 	DeleteSlot(appId, slotId string) error
 
 	UpdateCurrentTask(appId, slotId string, task *Task) error
+	AppendTaskHistory(appId, slotId string, task *Task) error
 	ListTaskHistory(appId, slotId string) []*Task
 
 	UpdateFrameworkId(frameworkId string) error
@@ -31,6 +32,24 @@ type Store interface {
 	DeleteOfferAllocatorItem(slotId string) error
 	ListOfferallocatorItems() []*OfferAllocatorItem
 
+	CreateGroup(group *Group) error
+	UpdateGroup(group *Group) error
+	GetGroup(groupId string) *Group
+	ListGroups() []*Group
+	DeleteGroup(groupId string) error
+
+	CreateJob(job *Job) error
+	UpdateJob(job *Job) error
+	GetJob(jobId string) *Job
+	ListJobs() []*Job
+	DeleteJob(jobId string) error
+
+	CreateQuota(quota *Quota) error
+	UpdateQuota(quota *Quota) error
+	GetQuota(runAs string) *Quota
+	ListQuotas() []*Quota
+	DeleteQuota(runAs string) error
+
 	Recover() error
 	Start(context.Context) error
 }
@@ -0,0 +1,64 @@
+package store
+
+func (zk *ZkStore) CreateJob(job *Job) error {
+	if zk.GetJob(job.ID) != nil {
+		return ErrJobAlreadyExists
+	}
+
+	op := &AtomicOp{
+		Op:      OP_ADD,
+		Entity:  ENTITY_JOB,
+		Param1:  job.ID,
+		Payload: job,
+	}
+
+	return zk.Apply(op, true)
+}
+
+func (zk *ZkStore) UpdateJob(job *Job) error {
+	if zk.GetJob(job.ID) == nil {
+		return ErrJobNotFound
+	}
+
+	op := &AtomicOp{
+		Op:      OP_UPDATE,
+		Entity:  ENTITY_JOB,
+		Param1:  job.ID,
+		Payload: job,
+	}
+
+	return zk.Apply(op, true)
+}
+
+func (zk *ZkStore) GetJob(jobId string) *Job {
+	zk.mu.RLock()
+	defer zk.mu.RUnlock()
+
+	return zk.Storage.Jobs[jobId]
+}
+
+func (zk *ZkStore) ListJobs() []*Job {
+	zk.mu.RLock()
+	defer zk.mu.RUnlock()
+
+	jobs := make([]*Job, 0)
+	for _, job := range zk.Storage.Jobs {
+		jobs = append(jobs, job)
+	}
+
+	return jobs
+}
+
+func (zk *ZkStore) DeleteJob(jobId string) error {
+	if zk.GetJob(jobId) == nil {
+		return ErrJobNotFound
+	}
+
+	op := &AtomicOp{
+		Op:     OP_REMOVE,
+		Entity: ENTITY_JOB,
+		Param1: jobId,
+	}
+
+	return zk.Apply(op, true)
+}
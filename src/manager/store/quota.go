@@ -0,0 +1,64 @@
+package store
+
+func (zk *ZkStore) CreateQuota(quota *Quota) error {
+	if zk.GetQuota(quota.RunAs) != nil {
+		return ErrQuotaAlreadyExists
+	}
+
+	op := &AtomicOp{
+		Op:      OP_ADD,
+		Entity:  ENTITY_QUOTA,
+		Param1:  quota.RunAs,
+		Payload: quota,
+	}
+
+	return zk.Apply(op, true)
+}
+
+func (zk *ZkStore) UpdateQuota(quota *Quota) error {
+	if zk.GetQuota(quota.RunAs) == nil {
+		return ErrQuotaNotFound
+	}
+
+	op := &AtomicOp{
+		Op:      OP_UPDATE,
+		Entity:  ENTITY_QUOTA,
+		Param1:  quota.RunAs,
+		Payload: quota,
+	}
+
+	return zk.Apply(op, true)
+}
+
+func (zk *ZkStore) GetQuota(runAs string) *Quota {
+	zk.mu.RLock()
+	defer zk.mu.RUnlock()
+
+	return zk.Storage.Quotas[runAs]
+}
+
+func (zk *ZkStore) ListQuotas() []*Quota {
+	zk.mu.RLock()
+	defer zk.mu.RUnlock()
+
+	quotas := make([]*Quota, 0)
+	for _, quota := range zk.Storage.Quotas {
+		quotas = append(quotas, quota)
+	}
+
+	return quotas
+}
+
+func (zk *ZkStore) DeleteQuota(runAs string) error {
+	if zk.GetQuota(runAs) == nil {
+		return ErrQuotaNotFound
+	}
+
+	op := &AtomicOp{
+		Op:     OP_REMOVE,
+		Entity: ENTITY_QUOTA,
+		Param1: runAs,
+	}
+
+	return zk.Apply(op, true)
+}
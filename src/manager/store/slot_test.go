@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the same operations as slots reindexed by state, without persisting to zk
+func TestTaskHistoryCarriedOverOnReindex(t *testing.T) {
+	zk := &ZkStore{Storage: NewStorage()}
+	now := time.Now().UnixNano()
+	apply := func(op *AtomicOp) {
+		assert.Nil(t, zk.Apply(op, false))
+	}
+
+	apply(&AtomicOp{Op: OP_ADD, Entity: ENTITY_APP, Param1: "app", Payload: &Application{ID: "app"}})
+	apply(&AtomicOp{Op: OP_ADD, Entity: ENTITY_SLOT, Param1: "app", Param2: "1-app", Payload: &Slot{ID: "1-app", AppID: "app"}})
+	apply(&AtomicOp{Op: OP_ADD, Entity: ENTITY_TASK_HISTORY, Param1: "app", Param2: "1-app", Payload: &Task{ID: "task-0", ArchivedAt: now}})
+	apply(&AtomicOp{Op: OP_ADD, Entity: ENTITY_TASK_HISTORY, Param1: "app", Param2: "1-app", Payload: &Task{ID: "task-1", ArchivedAt: now}})
+
+	history := zk.ListTaskHistory("app", "1-app")
+	assert.Len(t, history, 2)
+
+	apply(&AtomicOp{Op: OP_REMOVE, Entity: ENTITY_SLOT, Param1: "app", Param2: "1-app"})
+	apply(&AtomicOp{Op: OP_ADD, Entity: ENTITY_SLOT, Param1: "app", Param2: "0-app",
+		Payload: &Slot{ID: "0-app", AppID: "app", TaskHistory: history}})
+
+	assert.Nil(t, zk.ListTaskHistory("app", "1-app"))
+
+	ids := make([]string, 0)
+	for _, task := range zk.ListTaskHistory("app", "0-app") {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"task-0", "task-1"}, ids)
+
+	// history is kept by later updates of the slot
+	apply(&AtomicOp{Op: OP_UPDATE, Entity: ENTITY_SLOT, Param1: "app", Param2: "0-app", Payload: &Slot{ID: "0-app", AppID: "app"}})
+	assert.Len(t, zk.ListTaskHistory("app", "0-app"), 2)
+}
@@ -15,6 +15,9 @@ type Application struct {
 	CreatedAt       int64         `json:"createdAt,omitempty"`
 	UpdatedAt       int64         `json:"updatedAt,omitempty"`
 	State           string        `json:"State,omitempty"`
+	Rollbacks       []*Rollback   `json:"rollbacks,omitempty"`
+	// app carrying out a run of a job
+	Job bool `json:"job,omitempty"`
 }
 
 func (app *Application) Bytes() []byte {
@@ -31,6 +34,13 @@ func (app *Application) FromBytes(buf []byte) *Application {
 	return app
 }
 
+type Rollback struct {
+	ID            string `json:"id,omitempty"`
+	FromVersionID string `json:"fromVersionId,omitempty"`
+	ToVersionID   string `json:"toVersionId,omitempty"`
+	CreatedAt     int64  `json:"createdAt,omitempty"`
+}
+
 type Version struct {
 	ID           string            `json:"id,omitempty"`
 	Command      string            `json:"command,omitempty"`
@@ -55,6 +65,33 @@ type Version struct {
 	Priority     int32             `json:"priority,omitempty"`
 	Args         []string          `json:"args,omitempty"`
 	AppVersion   string            `json:"appVersion,omitempty"`
+
+	BackoffSeconds        float64 `json:"backoffSeconds,omitempty"`
+	BackoffFactor         float64 `json:"backoffFactor,omitempty"`
+	MaxLaunchDelaySeconds float64 `json:"maxLaunchDelaySeconds,omitempty"`
+	MaxRestarts           int32   `json:"maxRestarts,omitempty"`
+	RestartPolicy         string  `json:"restartPolicy,omitempty"`
+
+	ReadinessCheck *ReadinessCheck `json:"readinessCheck,omitempty"`
+	PreStop        *PreStop        `json:"preStop,omitempty"`
+
+	NonPreemptible bool `json:"nonPreemptible,omitempty"`
+}
+
+type PreStop struct {
+	PortName       string  `json:"portName,omitempty"`
+	Path           string  `json:"path,omitempty"`
+	TimeoutSeconds float64 `json:"timeoutSeconds,omitempty"`
+	DrainSeconds   float64 `json:"drainSeconds,omitempty"`
+}
+
+type ReadinessCheck struct {
+	Protocol        string  `json:"protocol,omitempty"`
+	PortName        string  `json:"portName,omitempty"`
+	Path            string  `json:"path,omitempty"`
+	IntervalSeconds float64 `json:"intervalSeconds,omitempty"`
+	TimeoutSeconds  float64 `json:"timeoutSeconds,omitempty"`
+	DelaySeconds    float64 `json:"delaySeconds,omitempty"`
 }
 
 func (version *Version) Bytes() []byte {
@@ -109,10 +146,21 @@ type KillPolicy struct {
 }
 
 type UpdatePolicy struct {
-	UpdateDelay  int32  `json:"updateDelay,omitempty"`
-	MaxRetries   int32  `json:"maxRetries,omitempty"`
-	MaxFailovers int32  `json:"maxFailovers,omitempty"`
-	Action       string `json:"action,omitempty"`
+	BatchSize      int32         `json:"batchSize,omitempty"`
+	UpdateDelay    int32         `json:"updateDelay,omitempty"`
+	MaxFailovers   int32         `json:"maxFailovers,omitempty"`
+	Action         string        `json:"action,omitempty"`
+	MaxSurge       int32         `json:"maxSurge,omitempty"`
+	MaxUnavailable int32         `json:"maxUnavailable,omitempty"`
+	Strategy       string        `json:"strategy,omitempty"`
+	Canary         *CanaryPolicy `json:"canary,omitempty"`
+}
+
+type CanaryPolicy struct {
+	Instances    int32     `json:"instances,omitempty"`
+	WeightSteps  []float64 `json:"weightSteps,omitempty"`
+	StepInterval int32     `json:"stepInterval,omitempty"`
+	MaxErrorRate float64   `json:"maxErrorRate,omitempty"`
 }
 
 type Gateway struct {
@@ -134,6 +182,12 @@ type HealthCheck struct {
 	IntervalSeconds     float64 `json:"intervalSeconds,omitempty"`
 	TimeoutSeconds      float64 `json:"timeoutSeconds,omitempty"`
 	DelaySeconds        float64 `json:"delaySeconds,omitempty"`
+
+	KillAfterUnhealthySeconds float64 `json:"killAfterUnhealthySeconds,omitempty"`
+
+	Statuses []uint32          `json:"statuses,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Service  string            `json:"service,omitempty"`
 }
 
 type Slot struct {
@@ -149,6 +203,23 @@ type Slot struct {
 	TaskHistory          []*Task        `json:"TaskHistory,omitempty"`
 	RestartPolicy        *RestartPolicy `json:"restartPolicy,omitempty"`
 	Weight               float64        `json:"weight,omitempty"`
+	Standby              bool           `json:"standby,omitempty"`
+	Health               *TaskHealth    `json:"health,omitempty"`
+	Ready                bool           `json:"ready,omitempty"`
+}
+
+type TaskHealth struct {
+	Healthy             bool                  `json:"healthy,omitempty"`
+	LastCheckAt         int64                 `json:"lastCheckAt,omitempty"`
+	LastTransitionAt    int64                 `json:"lastTransitionAt,omitempty"`
+	ConsecutiveFailures int32                 `json:"consecutiveFailures,omitempty"`
+	Outcomes            []*HealthCheckOutcome `json:"outcomes,omitempty"`
+}
+
+type HealthCheckOutcome struct {
+	At      int64  `json:"at,omitempty"`
+	Healthy bool   `json:"healthy,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 func (slot *Slot) Bytes() []byte {
@@ -183,6 +254,7 @@ type Task struct {
 	AgentHostName string   `json:"agentHostName,omitempty"`
 	Reason        string   `json:"reason,omitempty"`
 	Message       string   `json:"message,omitempty"`
+	Source        string   `json:"source,omitempty"`
 	CreatedAt     int64    `json:"createdAt,omitempty"`
 	ArchivedAt    int64    `json:"archivedAt,omitempty"`
 	ContainerId   string   `json:"containerId,omitempty"`
@@ -197,13 +269,95 @@ type OfferAllocatorItem struct {
 	AgentID  string `json:"agentId,omitempty"`
 }
 
+type Group struct {
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	RunAs     string         `json:"runAs,omitempty"`
+	ClusterID string         `json:"clusterId,omitempty"`
+	State     string         `json:"state,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Timeout   int            `json:"timeout,omitempty"`
+	Members   []*GroupMember `json:"members,omitempty"`
+	CreatedAt int64          `json:"createdAt,omitempty"`
+	UpdatedAt int64          `json:"updatedAt,omitempty"`
+}
+
+type GroupMember struct {
+	AppID     string   `json:"appId,omitempty"`
+	AppName   string   `json:"appName,omitempty"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// version to apply while group operation is in progress
+	Version *Version `json:"version,omitempty"`
+	// version to roll back to if group update failed
+	PreviousVersionID string `json:"previousVersionId,omitempty"`
+	// target instances of scaling, -1 if left unchanged
+	Instances int32    `json:"instances,omitempty"`
+	IPs       []string `json:"ips,omitempty"`
+	// instances and ips to restore if group scaling failed
+	PreviousInstances int32    `json:"previousInstances,omitempty"`
+	PreviousIPs       []string `json:"previousIps,omitempty"`
+}
+
+type Job struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	RunAs       string `json:"runAs,omitempty"`
+	ClusterID   string `json:"clusterId,omitempty"`
+	Schedule    string `json:"schedule,omitempty"`
+	Parallelism int32  `json:"parallelism,omitempty"`
+	Retries     int32  `json:"retries,omitempty"`
+	// task template of each run
+	Version   *Version  `json:"version,omitempty"`
+	Runs      []*JobRun `json:"runs,omitempty"`
+	CreatedAt int64     `json:"createdAt,omitempty"`
+	UpdatedAt int64     `json:"updatedAt,omitempty"`
+}
+
+type JobRun struct {
+	ID         string        `json:"id,omitempty"`
+	State      string        `json:"state,omitempty"`
+	Trigger    string        `json:"trigger,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	Tasks      []*JobRunTask `json:"tasks,omitempty"`
+	StartedAt  int64         `json:"startedAt,omitempty"`
+	FinishedAt int64         `json:"finishedAt,omitempty"`
+}
+
+type JobRunTask struct {
+	Index         int32  `json:"index,omitempty"`
+	TaskID        string `json:"taskId,omitempty"`
+	State         string `json:"state,omitempty"`
+	Attempts      int32  `json:"attempts,omitempty"`
+	AgentHostName string `json:"agentHostName,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	Message       string `json:"message,omitempty"`
+	// mesos task id of the latest attempt
+	LaunchedTaskID string `json:"launchedTaskId,omitempty"`
+}
+
+type Quota struct {
+	RunAs     string  `json:"runAs,omitempty"`
+	Cpus      float64 `json:"cpus,omitempty"`
+	Mem       float64 `json:"mem,omitempty"`
+	Disk      float64 `json:"disk,omitempty"`
+	Instances int32   `json:"instances,omitempty"`
+	CreatedAt int64   `json:"createdAt,omitempty"`
+	UpdatedAt int64   `json:"updatedAt,omitempty"`
+}
+
 type StateMachine struct {
 	State *State `json:"state,omitempty"`
 }
 
 type State struct {
-	Name                string `json:"name,omitempty"`
-	CurrentSlotIndex    int64  `json:"currentSlotIndex,omitempty"`
-	TargetSlotIndex     int64  `json:"targetSlotIndex,omitempty"`
-	SlotCountNeedUpdate int64  `json:"slotCountNeedUpdate,omitempty"`
+	Name                string  `json:"name,omitempty"`
+	CurrentSlotIndex    int64   `json:"currentSlotIndex,omitempty"`
+	TargetSlotIndex     int64   `json:"targetSlotIndex,omitempty"`
+	SlotCountNeedUpdate int64   `json:"slotCountNeedUpdate,omitempty"`
+	Failovers           int64   `json:"failovers,omitempty"`
+	Paused              bool    `json:"paused,omitempty"`
+	CanaryStep          int64   `json:"canaryStep,omitempty"`
+	Offset              int64   `json:"offset,omitempty"`
+	Flipped             bool    `json:"flipped,omitempty"`
+	Indices             []int64 `json:"indices,omitempty"`
 }
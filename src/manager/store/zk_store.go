@@ -62,6 +62,10 @@ var (
 	ENTITY_CURRENT_TASK         StoreEntity = 4
 	ENTITY_FRAMEWORKID          StoreEntity = 5
 	ENTITY_OFFER_ALLOCATOR_ITEM StoreEntity = 6
+	ENTITY_GROUP                StoreEntity = 7
+	ENTITY_JOB                  StoreEntity = 8
+	ENTITY_TASK_HISTORY         StoreEntity = 9
+	ENTITY_QUOTA                StoreEntity = 10
 )
 
 func (entity StoreEntity) String() string {
@@ -78,6 +82,14 @@ func (entity StoreEntity) String() string {
 		return "ENTITY_FRAMEWORKID"
 	case ENTITY_OFFER_ALLOCATOR_ITEM:
 		return "ENTITY_OFFER_ALLOCATOR_ITEM"
+	case ENTITY_GROUP:
+		return "ENTITY_GROUP"
+	case ENTITY_JOB:
+		return "ENTITY_JOB"
+	case ENTITY_TASK_HISTORY:
+		return "ENTITY_TASK_HISTORY"
+	case ENTITY_QUOTA:
+		return "ENTITY_QUOTA"
 	}
 
 	return ""
@@ -89,6 +101,12 @@ var (
 	ErrSlotNotFound         = errors.New("slot not found")
 	ErrSlotAlreadyExists    = errors.New("slot already exists")
 	ErrVersionAlreadyExists = errors.New("version already exists")
+	ErrGroupNotFound        = errors.New("group not found")
+	ErrGroupAlreadyExists   = errors.New("group already exists")
+	ErrJobNotFound          = errors.New("job not found")
+	ErrJobAlreadyExists     = errors.New("job already exists")
+	ErrQuotaNotFound        = errors.New("quota not found")
+	ErrQuotaAlreadyExists   = errors.New("quota already exists")
 )
 
 type AtomicOp struct {
@@ -116,12 +134,18 @@ type Storage struct {
 	Apps           map[string]*appHolder          `json:"apps"`
 	OfferAllocator map[string]*OfferAllocatorItem `json:"offerAllocator"`
 	FrameworkId    string                         `json:"frameworkid"`
+	Groups         map[string]*Group              `json:"groups"`
+	Jobs           map[string]*Job                `json:"jobs"`
+	Quotas         map[string]*Quota              `json:"quotas"`
 }
 
 func NewStorage() *Storage {
 	return &Storage{
 		Apps:           make(map[string]*appHolder),
 		OfferAllocator: make(map[string]*OfferAllocatorItem),
+		Groups:         make(map[string]*Group),
+		Jobs:           make(map[string]*Job),
+		Quotas:         make(map[string]*Quota),
 	}
 }
 
@@ -163,6 +187,13 @@ func InitZkStore(zkPath *url.URL) error {
 	return nil
 }
 
+// store kept in memory only, for tests of packages built upon it
+func InitMemoryStore() {
+	zs = &ZkStore{
+		Storage: NewStorage(),
+	}
+}
+
 func (zk *ZkStore) Start(ctx context.Context) error {
 	ticker := time.NewTicker(2 * time.Second)
 	for {
@@ -206,11 +237,19 @@ func (zk *ZkStore) Apply(op *AtomicOp, zkPersistNeeded bool) error {
 		applyOk = zk.applyCurrentTask(op)
 	case ENTITY_OFFER_ALLOCATOR_ITEM:
 		applyOk = zk.applyOfferAllocatorItem(op)
+	case ENTITY_GROUP:
+		applyOk = zk.applyGroup(op)
+	case ENTITY_JOB:
+		applyOk = zk.applyJob(op)
+	case ENTITY_TASK_HISTORY:
+		applyOk = zk.applyTaskHistory(op)
+	case ENTITY_QUOTA:
+		applyOk = zk.applyQuota(op)
 	default:
 		panic("invalid entity type")
 	}
 
-	if zkPersistNeeded && applyOk {
+	if zkPersistNeeded && applyOk && zk.conn != nil {
 		var buf bytes.Buffer
 		enc := json.NewEncoder(&buf)
 		err := enc.Encode(op)
@@ -251,6 +290,60 @@ func (zk *ZkStore) applyOfferAllocatorItem(op *AtomicOp) bool {
 	return true
 }
 
+func (zk *ZkStore) applyGroup(op *AtomicOp) bool {
+	switch op.Op {
+	case OP_ADD:
+		zk.Storage.Groups[op.Param1] = op.Payload.(*Group)
+	case OP_REMOVE:
+		delete(zk.Storage.Groups, op.Param1)
+	case OP_UPDATE:
+		if _, found := zk.Storage.Groups[op.Param1]; !found {
+			return false
+		}
+		zk.Storage.Groups[op.Param1] = op.Payload.(*Group)
+	default:
+		panic("applyGroup not supportted operation")
+	}
+
+	return true
+}
+
+func (zk *ZkStore) applyJob(op *AtomicOp) bool {
+	switch op.Op {
+	case OP_ADD:
+		zk.Storage.Jobs[op.Param1] = op.Payload.(*Job)
+	case OP_REMOVE:
+		delete(zk.Storage.Jobs, op.Param1)
+	case OP_UPDATE:
+		if _, found := zk.Storage.Jobs[op.Param1]; !found {
+			return false
+		}
+		zk.Storage.Jobs[op.Param1] = op.Payload.(*Job)
+	default:
+		panic("applyJob not supportted operation")
+	}
+
+	return true
+}
+
+func (zk *ZkStore) applyQuota(op *AtomicOp) bool {
+	switch op.Op {
+	case OP_ADD:
+		zk.Storage.Quotas[op.Param1] = op.Payload.(*Quota)
+	case OP_REMOVE:
+		delete(zk.Storage.Quotas, op.Param1)
+	case OP_UPDATE:
+		if _, found := zk.Storage.Quotas[op.Param1]; !found {
+			return false
+		}
+		zk.Storage.Quotas[op.Param1] = op.Payload.(*Quota)
+	default:
+		panic("applyQuota not supportted operation")
+	}
+
+	return true
+}
+
 func (zk *ZkStore) applyCurrentTask(op *AtomicOp) bool {
 	_, ok := zk.Storage.Apps[op.Param1]
 	if !ok {
@@ -272,6 +365,27 @@ func (zk *ZkStore) applyCurrentTask(op *AtomicOp) bool {
 	return true
 }
 
+func (zk *ZkStore) applyTaskHistory(op *AtomicOp) bool {
+	_, ok := zk.Storage.Apps[op.Param1]
+	if !ok {
+		return false
+	}
+
+	slot, ok := zk.Storage.Apps[op.Param1].Slots[op.Param2]
+	if !ok {
+		return false
+	}
+
+	switch op.Op {
+	case OP_ADD:
+		slot.TaskHistory = PruneTaskHistory(append(slot.TaskHistory, op.Payload.(*Task)), time.Now())
+	default:
+		panic("applyTaskHistory not supportted operation")
+	}
+
+	return true
+}
+
 func (zk *ZkStore) applySlot(op *AtomicOp) bool {
 	_, ok := zk.Storage.Apps[op.Param1]
 	if !ok {
@@ -284,7 +398,12 @@ func (zk *ZkStore) applySlot(op *AtomicOp) bool {
 	case OP_REMOVE:
 		delete(zk.Storage.Apps[op.Param1].Slots, op.Param2)
 	case OP_UPDATE:
-		zk.Storage.Apps[op.Param1].Slots[op.Param2] = op.Payload.(*Slot)
+		slot := op.Payload.(*Slot)
+		// task history is appended by its own operations only
+		if old, found := zk.Storage.Apps[op.Param1].Slots[op.Param2]; found && len(slot.TaskHistory) == 0 {
+			slot.TaskHistory = old.TaskHistory
+		}
+		zk.Storage.Apps[op.Param1].Slots[op.Param2] = slot
 	default:
 		panic("applySlot not supported operation")
 	}
@@ -379,7 +498,7 @@ func (zk *ZkStore) snapshot() (string, error) {
 	zk.mu.Unlock()
 
 	logrus.Debugf("snapshot storage to zk with data len: %d", len(data))
-	logrus.Debugf(string(data))
+	logrus.Debugf("%s", data)
 
 	exists, _, err := zk.conn.Exists(snapshotPath)
 	if err != nil {
@@ -516,7 +635,7 @@ func (zk *ZkStore) unmarshalAtomicOp(data []byte) (*AtomicOp, error) {
 				return nil, err
 			}
 			ao.Payload = &version
-		case ENTITY_CURRENT_TASK:
+		case ENTITY_CURRENT_TASK, ENTITY_TASK_HISTORY:
 			var task Task
 			err = json.Unmarshal(tmpAo.Payload, &task)
 			if err != nil {
@@ -539,6 +658,30 @@ func (zk *ZkStore) unmarshalAtomicOp(data []byte) (*AtomicOp, error) {
 				return nil, err
 			}
 			ao.Payload = &item
+
+		case ENTITY_GROUP:
+			var group Group
+			err = json.Unmarshal(tmpAo.Payload, &group)
+			if err != nil {
+				return nil, err
+			}
+			ao.Payload = &group
+
+		case ENTITY_JOB:
+			var job Job
+			err = json.Unmarshal(tmpAo.Payload, &job)
+			if err != nil {
+				return nil, err
+			}
+			ao.Payload = &job
+
+		case ENTITY_QUOTA:
+			var quota Quota
+			err = json.Unmarshal(tmpAo.Payload, &quota)
+			if err != nil {
+				return nil, err
+			}
+			ao.Payload = &quota
 		}
 	}
 	return &ao, nil
@@ -52,30 +52,36 @@ const (
 	// likely a network partition. In such a case the scheduler should
 	// close the existing subscription connection and resubscribe
 	// using a backoff strategy.
-	Event_HEARTBEAT Event_Type = 8
+	Event_HEARTBEAT             Event_Type = 8
+	Event_INVERSE_OFFERS        Event_Type = 9
+	Event_RESCIND_INVERSE_OFFER Event_Type = 10
 )
 
 var Event_Type_name = map[int32]string{
-	0: "UNKNOWN",
-	1: "SUBSCRIBED",
-	2: "OFFERS",
-	3: "RESCIND",
-	4: "UPDATE",
-	5: "MESSAGE",
-	6: "FAILURE",
-	7: "ERROR",
-	8: "HEARTBEAT",
+	0:  "UNKNOWN",
+	1:  "SUBSCRIBED",
+	2:  "OFFERS",
+	3:  "RESCIND",
+	4:  "UPDATE",
+	5:  "MESSAGE",
+	6:  "FAILURE",
+	7:  "ERROR",
+	8:  "HEARTBEAT",
+	9:  "INVERSE_OFFERS",
+	10: "RESCIND_INVERSE_OFFER",
 }
 var Event_Type_value = map[string]int32{
-	"UNKNOWN":    0,
-	"SUBSCRIBED": 1,
-	"OFFERS":     2,
-	"RESCIND":    3,
-	"UPDATE":     4,
-	"MESSAGE":    5,
-	"FAILURE":    6,
-	"ERROR":      7,
-	"HEARTBEAT":  8,
+	"UNKNOWN":               0,
+	"SUBSCRIBED":            1,
+	"OFFERS":                2,
+	"RESCIND":               3,
+	"UPDATE":                4,
+	"MESSAGE":               5,
+	"FAILURE":               6,
+	"ERROR":                 7,
+	"HEARTBEAT":             8,
+	"INVERSE_OFFERS":        9,
+	"RESCIND_INVERSE_OFFER": 10,
 }
 
 func (x Event_Type) Enum() *Event_Type {
@@ -102,19 +108,21 @@ type Call_Type int32
 
 const (
 	// See comments above on `Event::Type` for more details on this enum value.
-	Call_UNKNOWN     Call_Type = 0
-	Call_SUBSCRIBE   Call_Type = 1
-	Call_TEARDOWN    Call_Type = 2
-	Call_ACCEPT      Call_Type = 3
-	Call_DECLINE     Call_Type = 4
-	Call_REVIVE      Call_Type = 5
-	Call_KILL        Call_Type = 6
-	Call_SHUTDOWN    Call_Type = 7
-	Call_ACKNOWLEDGE Call_Type = 8
-	Call_RECONCILE   Call_Type = 9
-	Call_MESSAGE     Call_Type = 10
-	Call_REQUEST     Call_Type = 11
-	Call_SUPPRESS    Call_Type = 12
+	Call_UNKNOWN                Call_Type = 0
+	Call_SUBSCRIBE              Call_Type = 1
+	Call_TEARDOWN               Call_Type = 2
+	Call_ACCEPT                 Call_Type = 3
+	Call_DECLINE                Call_Type = 4
+	Call_REVIVE                 Call_Type = 5
+	Call_KILL                   Call_Type = 6
+	Call_SHUTDOWN               Call_Type = 7
+	Call_ACKNOWLEDGE            Call_Type = 8
+	Call_RECONCILE              Call_Type = 9
+	Call_MESSAGE                Call_Type = 10
+	Call_REQUEST                Call_Type = 11
+	Call_SUPPRESS               Call_Type = 12
+	Call_ACCEPT_INVERSE_OFFERS  Call_Type = 13
+	Call_DECLINE_INVERSE_OFFERS Call_Type = 14
 )
 
 var Call_Type_name = map[int32]string{
@@ -131,21 +139,25 @@ var Call_Type_name = map[int32]string{
 	10: "MESSAGE",
 	11: "REQUEST",
 	12: "SUPPRESS",
+	13: "ACCEPT_INVERSE_OFFERS",
+	14: "DECLINE_INVERSE_OFFERS",
 }
 var Call_Type_value = map[string]int32{
-	"UNKNOWN":     0,
-	"SUBSCRIBE":   1,
-	"TEARDOWN":    2,
-	"ACCEPT":      3,
-	"DECLINE":     4,
-	"REVIVE":      5,
-	"KILL":        6,
-	"SHUTDOWN":    7,
-	"ACKNOWLEDGE": 8,
-	"RECONCILE":   9,
-	"MESSAGE":     10,
-	"REQUEST":     11,
-	"SUPPRESS":    12,
+	"UNKNOWN":                0,
+	"SUBSCRIBE":              1,
+	"TEARDOWN":               2,
+	"ACCEPT":                 3,
+	"DECLINE":                4,
+	"REVIVE":                 5,
+	"KILL":                   6,
+	"SHUTDOWN":               7,
+	"ACKNOWLEDGE":            8,
+	"RECONCILE":              9,
+	"MESSAGE":                10,
+	"REQUEST":                11,
+	"SUPPRESS":               12,
+	"ACCEPT_INVERSE_OFFERS":  13,
+	"DECLINE_INVERSE_OFFERS": 14,
 }
 
 func (x Call_Type) Enum() *Call_Type {
@@ -176,15 +188,17 @@ type Event struct {
 	// Type of the event, indicates which optional field below should be
 	// present if that type has a nested message definition.
 	// Enum fields should be optional, see: MESOS-4997.
-	Type             *Event_Type       `protobuf:"varint,1,opt,name=type,enum=mesos.Event_Type" json:"type,omitempty"`
-	Subscribed       *Event_Subscribed `protobuf:"bytes,2,opt,name=subscribed" json:"subscribed,omitempty"`
-	Offers           *Event_Offers     `protobuf:"bytes,3,opt,name=offers" json:"offers,omitempty"`
-	Rescind          *Event_Rescind    `protobuf:"bytes,4,opt,name=rescind" json:"rescind,omitempty"`
-	Update           *Event_Update     `protobuf:"bytes,5,opt,name=update" json:"update,omitempty"`
-	Message          *Event_Message    `protobuf:"bytes,6,opt,name=message" json:"message,omitempty"`
-	Failure          *Event_Failure    `protobuf:"bytes,7,opt,name=failure" json:"failure,omitempty"`
-	Error            *Event_Error      `protobuf:"bytes,8,opt,name=error" json:"error,omitempty"`
-	XXX_unrecognized []byte            `json:"-"`
+	Type                *Event_Type                `protobuf:"varint,1,opt,name=type,enum=mesos.Event_Type" json:"type,omitempty"`
+	Subscribed          *Event_Subscribed          `protobuf:"bytes,2,opt,name=subscribed" json:"subscribed,omitempty"`
+	Offers              *Event_Offers              `protobuf:"bytes,3,opt,name=offers" json:"offers,omitempty"`
+	Rescind             *Event_Rescind             `protobuf:"bytes,4,opt,name=rescind" json:"rescind,omitempty"`
+	Update              *Event_Update              `protobuf:"bytes,5,opt,name=update" json:"update,omitempty"`
+	Message             *Event_Message             `protobuf:"bytes,6,opt,name=message" json:"message,omitempty"`
+	Failure             *Event_Failure             `protobuf:"bytes,7,opt,name=failure" json:"failure,omitempty"`
+	Error               *Event_Error               `protobuf:"bytes,8,opt,name=error" json:"error,omitempty"`
+	InverseOffers       *Event_InverseOffers       `protobuf:"bytes,9,opt,name=inverse_offers,json=inverseOffers" json:"inverse_offers,omitempty"`
+	RescindInverseOffer *Event_RescindInverseOffer `protobuf:"bytes,10,opt,name=rescind_inverse_offer,json=rescindInverseOffer" json:"rescind_inverse_offer,omitempty"`
+	XXX_unrecognized    []byte                     `json:"-"`
 }
 
 func (m *Event) Reset()                    { *m = Event{} }
@@ -248,6 +262,20 @@ func (m *Event) GetError() *Event_Error {
 	return nil
 }
 
+func (m *Event) GetInverseOffers() *Event_InverseOffers {
+	if m != nil {
+		return m.InverseOffers
+	}
+	return nil
+}
+
+func (m *Event) GetRescindInverseOffer() *Event_RescindInverseOffer {
+	if m != nil {
+		return m.RescindInverseOffer
+	}
+	return nil
+}
+
 // First event received when the scheduler subscribes.
 type Event_Subscribed struct {
 	FrameworkId *mesos.FrameworkID `protobuf:"bytes,1,req,name=framework_id,json=frameworkId" json:"framework_id,omitempty"`
@@ -329,6 +357,50 @@ func (m *Event_Rescind) GetOfferId() *mesos.OfferID {
 	return nil
 }
 
+// Received whenever there are resources requested back from the
+// scheduler. Each inverse offer specifies the agent, and
+// optionally specific resources. Accepting or Declining an inverse
+// offer informs the allocator of the scheduler's ability to release
+// the specified resources without violating an SLA. If no resources
+// are specified then all resources on the agent are requested to be
+// released.
+type Event_InverseOffers struct {
+	InverseOffers    []*mesos.InverseOffer `protobuf:"bytes,1,rep,name=inverse_offers,json=inverseOffers" json:"inverse_offers,omitempty"`
+	XXX_unrecognized []byte                `json:"-"`
+}
+
+func (m *Event_InverseOffers) Reset()         { *m = Event_InverseOffers{} }
+func (m *Event_InverseOffers) String() string { return proto.CompactTextString(m) }
+func (*Event_InverseOffers) ProtoMessage()    {}
+
+func (m *Event_InverseOffers) GetInverseOffers() []*mesos.InverseOffer {
+	if m != nil {
+		return m.InverseOffers
+	}
+	return nil
+}
+
+// Received when a particular inverse offer is no longer valid
+// (e.g., the agent corresponding to the offer has been removed)
+// and hence needs to be rescinded. Any future calls ('AcceptInverseOffers' /
+// 'DeclineInverseOffers') made by the scheduler regarding this inverse offer
+// will be invalid.
+type Event_RescindInverseOffer struct {
+	InverseOfferId   *mesos.OfferID `protobuf:"bytes,1,req,name=inverse_offer_id,json=inverseOfferId" json:"inverse_offer_id,omitempty"`
+	XXX_unrecognized []byte         `json:"-"`
+}
+
+func (m *Event_RescindInverseOffer) Reset()         { *m = Event_RescindInverseOffer{} }
+func (m *Event_RescindInverseOffer) String() string { return proto.CompactTextString(m) }
+func (*Event_RescindInverseOffer) ProtoMessage()    {}
+
+func (m *Event_RescindInverseOffer) GetInverseOfferId() *mesos.OfferID {
+	if m != nil {
+		return m.InverseOfferId
+	}
+	return nil
+}
+
 // Received whenever there is a status update that is generated by
 // the executor or agent or master. Status updates should be used by
 // executors to reliably communicate the status of the tasks that
@@ -475,17 +547,19 @@ type Call struct {
 	// Type of the call, indicates which optional field below should be
 	// present if that type has a nested message definition.
 	// See comments on `Event::Type` above on the reasoning behind this field being optional.
-	Type             *Call_Type        `protobuf:"varint,2,opt,name=type,enum=mesos.Call_Type" json:"type,omitempty"`
-	Subscribe        *Call_Subscribe   `protobuf:"bytes,3,opt,name=subscribe" json:"subscribe,omitempty"`
-	Accept           *Call_Accept      `protobuf:"bytes,4,opt,name=accept" json:"accept,omitempty"`
-	Decline          *Call_Decline     `protobuf:"bytes,5,opt,name=decline" json:"decline,omitempty"`
-	Kill             *Call_Kill        `protobuf:"bytes,6,opt,name=kill" json:"kill,omitempty"`
-	Shutdown         *Call_Shutdown    `protobuf:"bytes,7,opt,name=shutdown" json:"shutdown,omitempty"`
-	Acknowledge      *Call_Acknowledge `protobuf:"bytes,8,opt,name=acknowledge" json:"acknowledge,omitempty"`
-	Reconcile        *Call_Reconcile   `protobuf:"bytes,9,opt,name=reconcile" json:"reconcile,omitempty"`
-	Message          *Call_Message     `protobuf:"bytes,10,opt,name=message" json:"message,omitempty"`
-	Request          *Call_Request     `protobuf:"bytes,11,opt,name=request" json:"request,omitempty"`
-	XXX_unrecognized []byte            `json:"-"`
+	Type                 *Call_Type                 `protobuf:"varint,2,opt,name=type,enum=mesos.Call_Type" json:"type,omitempty"`
+	Subscribe            *Call_Subscribe            `protobuf:"bytes,3,opt,name=subscribe" json:"subscribe,omitempty"`
+	Accept               *Call_Accept               `protobuf:"bytes,4,opt,name=accept" json:"accept,omitempty"`
+	Decline              *Call_Decline              `protobuf:"bytes,5,opt,name=decline" json:"decline,omitempty"`
+	Kill                 *Call_Kill                 `protobuf:"bytes,6,opt,name=kill" json:"kill,omitempty"`
+	Shutdown             *Call_Shutdown             `protobuf:"bytes,7,opt,name=shutdown" json:"shutdown,omitempty"`
+	Acknowledge          *Call_Acknowledge          `protobuf:"bytes,8,opt,name=acknowledge" json:"acknowledge,omitempty"`
+	Reconcile            *Call_Reconcile            `protobuf:"bytes,9,opt,name=reconcile" json:"reconcile,omitempty"`
+	Message              *Call_Message              `protobuf:"bytes,10,opt,name=message" json:"message,omitempty"`
+	Request              *Call_Request              `protobuf:"bytes,11,opt,name=request" json:"request,omitempty"`
+	AcceptInverseOffers  *Call_AcceptInverseOffers  `protobuf:"bytes,13,opt,name=accept_inverse_offers,json=acceptInverseOffers" json:"accept_inverse_offers,omitempty"`
+	DeclineInverseOffers *Call_DeclineInverseOffers `protobuf:"bytes,14,opt,name=decline_inverse_offers,json=declineInverseOffers" json:"decline_inverse_offers,omitempty"`
+	XXX_unrecognized     []byte                     `json:"-"`
 }
 
 func (m *Call) Reset()                    { *m = Call{} }
@@ -570,6 +644,20 @@ func (m *Call) GetRequest() *Call_Request {
 	return nil
 }
 
+func (m *Call) GetAcceptInverseOffers() *Call_AcceptInverseOffers {
+	if m != nil {
+		return m.AcceptInverseOffers
+	}
+	return nil
+}
+
+func (m *Call) GetDeclineInverseOffers() *Call_DeclineInverseOffers {
+	if m != nil {
+		return m.DeclineInverseOffers
+	}
+	return nil
+}
+
 // Subscribes the scheduler with the master to receive events. A
 // scheduler must send other calls only after it has received the
 // SUBCRIBED event.
@@ -675,6 +763,60 @@ func (m *Call_Decline) GetFilters() *mesos.Filters {
 	return nil
 }
 
+// Accepts an inverse offer. Inverse offers should only be accepted
+// if the resources in the offer can be safely evacuated before the
+// provided unavailability.
+type Call_AcceptInverseOffers struct {
+	InverseOfferIds  []*mesos.OfferID `protobuf:"bytes,1,rep,name=inverse_offer_ids,json=inverseOfferIds" json:"inverse_offer_ids,omitempty"`
+	Filters          *mesos.Filters   `protobuf:"bytes,2,opt,name=filters" json:"filters,omitempty"`
+	XXX_unrecognized []byte           `json:"-"`
+}
+
+func (m *Call_AcceptInverseOffers) Reset()         { *m = Call_AcceptInverseOffers{} }
+func (m *Call_AcceptInverseOffers) String() string { return proto.CompactTextString(m) }
+func (*Call_AcceptInverseOffers) ProtoMessage()    {}
+
+func (m *Call_AcceptInverseOffers) GetInverseOfferIds() []*mesos.OfferID {
+	if m != nil {
+		return m.InverseOfferIds
+	}
+	return nil
+}
+
+func (m *Call_AcceptInverseOffers) GetFilters() *mesos.Filters {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+// Declines an inverse offer. Inverse offers should be declined if
+// the resources in the offer might not be safely evacuated before
+// the provided unavailability.
+type Call_DeclineInverseOffers struct {
+	InverseOfferIds  []*mesos.OfferID `protobuf:"bytes,1,rep,name=inverse_offer_ids,json=inverseOfferIds" json:"inverse_offer_ids,omitempty"`
+	Filters          *mesos.Filters   `protobuf:"bytes,2,opt,name=filters" json:"filters,omitempty"`
+	XXX_unrecognized []byte           `json:"-"`
+}
+
+func (m *Call_DeclineInverseOffers) Reset()         { *m = Call_DeclineInverseOffers{} }
+func (m *Call_DeclineInverseOffers) String() string { return proto.CompactTextString(m) }
+func (*Call_DeclineInverseOffers) ProtoMessage()    {}
+
+func (m *Call_DeclineInverseOffers) GetInverseOfferIds() []*mesos.OfferID {
+	if m != nil {
+		return m.InverseOfferIds
+	}
+	return nil
+}
+
+func (m *Call_DeclineInverseOffers) GetFilters() *mesos.Filters {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
 // Kills a specific task. If the scheduler has a custom executor,
 // the kill is forwarded to the executor and it is up to the
 // executor to kill the task and send a TASK_KILLED (or TASK_FAILED)
@@ -905,10 +1047,14 @@ func init() {
 	proto.RegisterType((*Event_Message)(nil), "mesos.Event.Message")
 	proto.RegisterType((*Event_Failure)(nil), "mesos.Event.Failure")
 	proto.RegisterType((*Event_Error)(nil), "mesos.Event.Error")
+	proto.RegisterType((*Event_InverseOffers)(nil), "mesos.Event.InverseOffers")
+	proto.RegisterType((*Event_RescindInverseOffer)(nil), "mesos.Event.RescindInverseOffer")
 	proto.RegisterType((*Call)(nil), "mesos.Call")
 	proto.RegisterType((*Call_Subscribe)(nil), "mesos.Call.Subscribe")
 	proto.RegisterType((*Call_Accept)(nil), "mesos.Call.Accept")
 	proto.RegisterType((*Call_Decline)(nil), "mesos.Call.Decline")
+	proto.RegisterType((*Call_AcceptInverseOffers)(nil), "mesos.Call.AcceptInverseOffers")
+	proto.RegisterType((*Call_DeclineInverseOffers)(nil), "mesos.Call.DeclineInverseOffers")
 	proto.RegisterType((*Call_Kill)(nil), "mesos.Call.Kill")
 	proto.RegisterType((*Call_Shutdown)(nil), "mesos.Call.Shutdown")
 	proto.RegisterType((*Call_Acknowledge)(nil), "mesos.Call.Acknowledge")
@@ -26,9 +26,10 @@ type App struct {
 	Tasks          []*Task  `json:"tasks,omitempty"`
 	CurrentVersion *Version `json:"currentVersion"`
 	// use when app updated, ProposedVersion can either be commit or revert
-	ProposedVersion *Version `json:"proposedVersion,omitempty"`
-	Versions        []string `json:"versions,omitempty"`
-	IP              []string `json:"ip,omitempty"`
+	ProposedVersion *Version    `json:"proposedVersion,omitempty"`
+	Versions        []string    `json:"versions,omitempty"`
+	Rollbacks       []*Rollback `json:"rollbacks,omitempty"`
+	IP              []string    `json:"ip,omitempty"`
 
 	// current version related info
 	Labels      map[string]string `json:"labels,omitempty"`
@@ -70,10 +71,30 @@ type Task struct {
 
 	Image   string `json:"image"`
 	Healthy bool   `json:"healthy"`
+	// readiness check passed, always true without readiness check
+	Ready bool `json:"ready"`
 
 	ContainerId   string  `json:"containerId"`
 	ContainerName string  `json:"containerName"`
 	Weight        float64 `json:"weight"`
+	Standby       bool    `json:"standby,omitempty"`
+
+	Health *TaskHealth `json:"health,omitempty"`
+}
+
+// health check results of the current task of a slot, as reported by mesos
+type TaskHealth struct {
+	Healthy             bool                  `json:"healthy"`
+	LastCheck           *time.Time            `json:"lastCheck,omitempty"`
+	LastTransition      *time.Time            `json:"lastTransition,omitempty"`
+	ConsecutiveFailures int                   `json:"consecutiveFailures"`
+	Outcomes            []*HealthCheckOutcome `json:"outcomes"`
+}
+
+type HealthCheckOutcome struct {
+	Time    time.Time `json:"time"`
+	Healthy bool      `json:"healthy"`
+	Message string    `json:"message,omitempty"`
 }
 
 type TaskHistory struct {
@@ -93,15 +114,25 @@ type TaskHistory struct {
 	State   string `json:"state,omitempty"`
 	Reason  string `json:"reason,omitempty"`
 	Message string `json:"message,omitempty"`
+	Source  string `json:"source,omitempty"`
 	Stdout  string `json:"stdout,omitempty"`
 	Stderr  string `json:"stderr,omitempty"`
 
-	ArchivedAt    time.Time `json:"archivedAt, omitempty"`
+	Created       time.Time `json:"created,omitempty"`
+	ArchivedAt    time.Time `json:"archivedAt,omitempty"`
 	ContainerId   string    `json:"containerId"`
 	ContainerName string    `json:"containerName"`
 	Weight        float64   `json:"weight,omitempty"`
 }
 
+// archived tasks of a slot, the latest first
+type TaskHistoryPage struct {
+	Total   int            `json:"total"`
+	Offset  int            `json:"offset"`
+	Limit   int            `json:"limit"`
+	History []*TaskHistory `json:"history"`
+}
+
 type Stats struct {
 	ClusterID string `json:"clusterID"`
 
@@ -128,6 +159,12 @@ type Stats struct {
 	DiskTotalUsed float64 `json:"diskTotalUsed"`
 
 	AppStats map[string]int `json:"appStats,omitempty"`
+
+	// number of slots waiting for offers by priority
+	PendingOfferSlots map[int32]int `json:"pendingOfferSlots"`
+
+	// quotas with usage by runAs user
+	Quotas map[string]*Quota `json:"quotas,omitempty"`
 }
 
 type ProceedUpdateParam struct {
@@ -142,6 +179,55 @@ type ScaleUpParam struct {
 
 type ScaleDownParam struct {
 	Instances int `json:"instances"`
+	// remove these slots instead of the highest ones, slots at the top are
+	// then moved into the indices left
+	Indices []int `json:"indices,omitempty"`
+	// remove slots with these ips, for apps in fixed mode
+	IPs []string `json:"ips,omitempty"`
+}
+
+type RollbackParam struct {
+	VersionID string `json:"versionID"`
+}
+
+// every rollback of an app is recorded as a history entry
+type Rollback struct {
+	ID            string    `json:"id"`
+	FromVersionID string    `json:"fromVersionID"`
+	ToVersionID   string    `json:"toVersionID"`
+	Created       time.Time `json:"created"`
+}
+
+// result of an update dry-run, nothing is changed
+type UpdatePreview struct {
+	Valid   bool           `json:"valid"`
+	Errors  []string       `json:"errors"`
+	Changes []*FieldChange `json:"changes"`
+	Slots   []*SlotPreview `json:"slots"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+type SlotPreview struct {
+	Index       int    `json:"index"`
+	ID          string `json:"id"`
+	VersionID   string `json:"versionID"`
+	NeedRestart bool   `json:"needRestart"`
+	// changed fields which make the task need restart
+	Reasons []string `json:"reasons"`
+}
+
+// command run in the container of a task, with stdout and stderr streamed back
+type ExecParam struct {
+	Cmd []string `json:"cmd"`
+}
+
+type ExecResult struct {
+	ExitCode int `json:"exitCode"`
 }
 
 type UpdateWeightParam struct {
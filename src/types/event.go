@@ -17,6 +17,11 @@ type TaskInfoEvent struct {
 	AppName        string  `json:"appName"`
 	SlotIndex      int     `json:"slotIndex"`
 	GatewayEnabled bool    `json:"gatewayEnabled"`
+
+	// health check results, only for task_healthy and task_unhealthy events
+	Health *TaskHealth `json:"health,omitempty"`
+	// why the task was killed by swan
+	Reason string `json:"reason,omitempty"`
 }
 
 type AppInfoEvent struct {
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"time"
+)
+
+// apps of a group are created, updated, scaled and deleted together,
+// in order of their dependencies
+type Group struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	RunAs     string      `json:"runAs"`
+	ClusterID string      `json:"clusterID,omitempty"`
+	State     string      `json:"state"`
+	Message   string      `json:"message,omitempty"`
+	Timeout   int         `json:"timeout,omitempty"`
+	Apps      []*GroupApp `json:"apps"`
+	Created   time.Time   `json:"created"`
+	Updated   time.Time   `json:"updated"`
+}
+
+type GroupApp struct {
+	AppID            string   `json:"appID"`
+	AppName          string   `json:"appName"`
+	DependsOn        []string `json:"dependsOn,omitempty"`
+	State            string   `json:"state,omitempty"`
+	Instances        int      `json:"instances"`
+	RunningInstances int      `json:"runningInstances"`
+}
+
+// used to create or update a group
+type GroupVersion struct {
+	Name string `json:"name"`
+	// seconds to wait for each app to be ready, 0 means wait forever
+	Timeout int            `json:"timeout,omitempty"`
+	Apps    []*GroupMember `json:"apps"`
+}
+
+type GroupMember struct {
+	// names of apps in the same group which should be ready before this one
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Version   *Version `json:"version"`
+}
+
+type ScaleGroupParam struct {
+	// target instances by app name
+	Instances map[string]int `json:"instances"`
+	// ips of new instances by app name, for apps in fixed mode
+	IPs map[string][]string `json:"ips,omitempty"`
+}
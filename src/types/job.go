@@ -0,0 +1,57 @@
+package types
+
+import (
+	"time"
+)
+
+// tasks of a job run to completion, either on demand or by a cron schedule
+type Job struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	RunAs       string   `json:"runAs"`
+	ClusterID   string   `json:"clusterID,omitempty"`
+	Schedule    string   `json:"schedule,omitempty"`
+	Parallelism int32    `json:"parallelism"`
+	Retries     int32    `json:"retries"`
+	Task        *Version `json:"task"`
+	// id of the run in progress
+	ActiveRun string     `json:"activeRun,omitempty"`
+	NextRun   *time.Time `json:"nextRun,omitempty"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+}
+
+type JobRun struct {
+	ID       string        `json:"id"`
+	JobID    string        `json:"jobID"`
+	State    string        `json:"state"`
+	Trigger  string        `json:"trigger"`
+	Message  string        `json:"message,omitempty"`
+	Tasks    []*JobRunTask `json:"tasks"`
+	Started  time.Time     `json:"started"`
+	Finished *time.Time    `json:"finished,omitempty"`
+}
+
+type JobRunTask struct {
+	Index         int    `json:"index"`
+	TaskID        string `json:"taskID"`
+	State         string `json:"state"`
+	Attempts      int    `json:"attempts"`
+	AgentHostName string `json:"agentHostName,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// used to create or update a job
+type JobSpec struct {
+	Name  string `json:"name"`
+	RunAs string `json:"runAs"`
+	// cron expression, jobs without schedule are only run on demand
+	Schedule string `json:"schedule,omitempty"`
+	// tasks launched by each run, the run succeeds once all of them finished
+	Parallelism int32 `json:"parallelism,omitempty"`
+	// times a failed task is relaunched before the run is failed
+	Retries int32 `json:"retries,omitempty"`
+	// appName, runAs and instances of the task are taken from the job
+	Task *Version `json:"task"`
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"time"
+)
+
+// limits of resources claimed by apps of a runAs user, 0 means unlimited
+type Quota struct {
+	RunAs     string  `json:"runAs"`
+	CPUs      float64 `json:"cpus"`
+	Mem       float64 `json:"mem"`
+	Disk      float64 `json:"disk"`
+	Instances int32   `json:"instances"`
+
+	// resources claimed by apps of the user now
+	Used *QuotaUsage `json:"used,omitempty"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// resources of all slots of apps, whether the tasks are running or not
+type QuotaUsage struct {
+	CPUs      float64 `json:"cpus"`
+	Mem       float64 `json:"mem"`
+	Disk      float64 `json:"disk"`
+	Instances int32   `json:"instances"`
+}
@@ -22,6 +22,24 @@ type Version struct {
 	Constraints  string            `json:"constraints,omitempty"`
 	URIs         []string          `json:"uris,omitempty"`
 	IP           []string          `json:"ip,omitempty"`
+
+	// seconds to wait before relaunching a terminated task, multiplied by
+	// BackoffFactor on each relaunch and capped by MaxLaunchDelaySeconds
+	BackoffSeconds        float64 `json:"backoffSeconds,omitempty"`
+	BackoffFactor         float64 `json:"backoffFactor,omitempty"`
+	MaxLaunchDelaySeconds float64 `json:"maxLaunchDelaySeconds,omitempty"`
+	// relaunches allowed per slot, 0 means no limit
+	MaxRestarts int32 `json:"maxRestarts,omitempty"`
+	// always, onFailure or never, default is always
+	RestartPolicy string `json:"restartPolicy,omitempty"`
+
+	// task takes traffic only after readiness check passed, failed readiness
+	// check never restarts the task
+	ReadinessCheck *ReadinessCheck `json:"readinessCheck,omitempty"`
+	PreStop        *PreStop        `json:"preStop,omitempty"`
+
+	// tasks are never killed for slots of higher priority apps
+	NonPreemptible bool `json:"nonPreemptible,omitempty"`
 }
 
 type Container struct {
@@ -62,10 +80,33 @@ type KillPolicy struct {
 }
 
 type UpdatePolicy struct {
-	UpdateDelay  int32  `json:"updateDelay,omitempty"`
-	MaxRetries   int32  `json:"maxRetries,omitempty"`
-	MaxFailovers int32  `json:"maxFailovers,omitempty"`
-	Action       string `json:"action,omitempty"`
+	// slots updated per step, rolling update proceeds automatically when greater than 0
+	BatchSize int32 `json:"batchSize,omitempty"`
+	// seconds to wait between two steps
+	UpdateDelay  int32 `json:"updateDelay,omitempty"`
+	MaxFailovers int32 `json:"maxFailovers,omitempty"`
+	// what to do when MaxFailovers exceeded, either rollback or pause
+	Action string `json:"action,omitempty"`
+	// extra slots of new version allowed to launch before old ones killed
+	MaxSurge int32 `json:"maxSurge,omitempty"`
+	// slots allowed to be out of service at the same time during update
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+	// rolling, canary or blueGreen, default is rolling
+	Strategy string        `json:"strategy,omitempty"`
+	Canary   *CanaryPolicy `json:"canary,omitempty"`
+}
+
+// canary slots run the proposed version while their share of gateway traffic
+// is raised step by step, the update is promoted after the last step
+type CanaryPolicy struct {
+	// count of slots updated first as canaries
+	Instances int32 `json:"instances,omitempty"`
+	// percentages of traffic routed to canaries on each step, eg. [5, 25, 50, 100]
+	WeightSteps []float64 `json:"weightSteps,omitempty"`
+	// seconds to wait between two steps
+	StepInterval int32 `json:"stepInterval,omitempty"`
+	// abort the update when ratio of gateway 5xx responses of canaries exceeds, 0 disables the check
+	MaxErrorRate float64 `json:"maxErrorRate,omitempty"`
 }
 
 type HealthCheck struct {
@@ -82,6 +123,39 @@ type HealthCheck struct {
 	IntervalSeconds     float64 `json:"intervalSeconds,omitempty"`
 	TimeoutSeconds      float64 `json:"timeoutSeconds,omitempty"`
 	DelaySeconds        float64 `json:"delaySeconds,omitempty"`
+	// running task reported unhealthy for longer than this is killed and
+	// relaunched by restart policy, 0 means never
+	KillAfterUnhealthySeconds float64 `json:"killAfterUnhealthySeconds,omitempty"`
+	// container port checked on user defined network, which has no port mappings
+	Port int32 `json:"port,omitempty"`
+	// accepted http(s) status codes, and request headers
+	Statuses []uint32          `json:"statuses,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	// service name of grpc health checking protocol, empty for the server overall
+	Service string `json:"service,omitempty"`
+}
+
+// performed by swan manager against the task until it passed once
+type ReadinessCheck struct {
+	// http or tcp
+	Protocol string `json:"protocol"`
+	PortName string `json:"portName"`
+	// required by http, any status in 2xx and 3xx means ready
+	Path            string  `json:"path,omitempty"`
+	IntervalSeconds float64 `json:"intervalSeconds,omitempty"`
+	TimeoutSeconds  float64 `json:"timeoutSeconds,omitempty"`
+	DelaySeconds    float64 `json:"delaySeconds,omitempty"`
+}
+
+// performed before task killed by rolling update and scale down, the task is
+// drained from proxy and dns first, then the hook is called and the task is
+// killed after drainSeconds
+type PreStop struct {
+	// http GET against the task by swan manager, skipped if path is empty
+	PortName       string  `json:"portName,omitempty"`
+	Path           string  `json:"path,omitempty"`
+	TimeoutSeconds float64 `json:"timeoutSeconds,omitempty"`
+	DrainSeconds   float64 `json:"drainSeconds,omitempty"`
 }
 
 type Gateway struct {